@@ -62,6 +62,9 @@ func (a *SqlxAdapter) GetAdapterName() string {
 
 // GetDialect 获取数据库方言
 func (a *SqlxAdapter) GetDialect() string {
+	if a.tx != nil {
+		return a.tx.DriverName()
+	}
 	if a.db != nil {
 		return a.db.DriverName()
 	}
@@ -129,11 +132,8 @@ func (a *SqlxAdapter) BatchInsert(ctx context.Context, table string, data []map[
 		return nil
 	}
 
-	// 获取列名
-	var columns []string
-	for col := range data[0] {
-		columns = append(columns, col)
-	}
+	// 获取列名 (排序保证列顺序稳定)
+	columns := sortedKeys(data[0])
 
 	// 构建批量插入SQL (优化: 使用 strings.Builder)
 	var queryBuf strings.Builder
@@ -215,7 +215,8 @@ func (a *SqlxAdapter) BatchUpdate(ctx context.Context, table string, data []map[
 		var whereClauses []string
 		var whereValues []interface{}
 
-		for col, val := range row {
+		for _, col := range sortedKeys(row) {
+			val := row[col]
 			isWhereColumn := false
 			for _, whereCol := range whereColumns {
 				if col == whereCol {
@@ -258,30 +259,33 @@ func (a *SqlxAdapter) BatchUpdate(ctx context.Context, table string, data []map[
 
 // 实现DatabaseInterface接口
 func (a *SqlxAdapter) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return a.db.Query(query, args...)
+	return a.conn().Query(query, args...)
 }
 
 func (a *SqlxAdapter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return a.db.QueryContext(ctx, query, args...)
+	return a.conn().QueryContext(ctx, query, args...)
 }
 
 func (a *SqlxAdapter) QueryRow(query string, args ...interface{}) *sql.Row {
-	return a.db.QueryRow(query, args...)
+	return a.conn().QueryRow(query, args...)
 }
 
 func (a *SqlxAdapter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return a.db.QueryRowContext(ctx, query, args...)
+	return a.conn().QueryRowContext(ctx, query, args...)
 }
 
 func (a *SqlxAdapter) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return a.db.Exec(query, args...)
+	return a.conn().Exec(query, args...)
 }
 
 func (a *SqlxAdapter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return a.db.ExecContext(ctx, query, args...)
+	return a.conn().ExecContext(ctx, query, args...)
 }
 
 func (a *SqlxAdapter) Begin() (TransactionInterface, error) {
+	if a.tx != nil {
+		return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
+	}
 	if a.db == nil {
 		return nil, errors.NewError(errors.ErrorCodeCacheStoreNotFound, errors.MsgNoDatabaseConnection)
 	}
@@ -293,6 +297,9 @@ func (a *SqlxAdapter) Begin() (TransactionInterface, error) {
 }
 
 func (a *SqlxAdapter) BeginTx(ctx context.Context, opts *sql.TxOptions) (TransactionInterface, error) {
+	if a.tx != nil {
+		return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
+	}
 	if a.db == nil {
 		return nil, errors.NewError(errors.ErrorCodeCacheStoreNotFound, errors.MsgNoDatabaseConnection)
 	}
@@ -304,7 +311,7 @@ func (a *SqlxAdapter) BeginTx(ctx context.Context, opts *sql.TxOptions) (Transac
 }
 
 func (a *SqlxAdapter) Prepare(query string) (StatementInterface, error) {
-	stmt, err := a.db.Prepare(query)
+	stmt, err := a.conn().Prepare(query)
 	if err != nil {
 		return nil, err
 	}
@@ -312,7 +319,7 @@ func (a *SqlxAdapter) Prepare(query string) (StatementInterface, error) {
 }
 
 func (a *SqlxAdapter) PrepareContext(ctx context.Context, query string) (StatementInterface, error) {
-	stmt, err := a.db.PrepareContext(ctx, query)
+	stmt, err := a.conn().PrepareContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -328,11 +335,17 @@ func (a *SqlxAdapter) PingContext(ctx context.Context) error {
 }
 
 func (a *SqlxAdapter) Commit() error {
-	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+	if a.tx == nil {
+		return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+	}
+	return a.tx.Commit()
 }
 
 func (a *SqlxAdapter) Rollback() error {
-	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+	if a.tx == nil {
+		return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+	}
+	return a.tx.Rollback()
 }
 
 func (a *SqlxAdapter) Close() error {
@@ -341,57 +354,81 @@ func (a *SqlxAdapter) Close() error {
 
 // 实现SqlxInterface特有方法
 func (a *SqlxAdapter) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
-	return a.db.Queryx(query, args...)
+	return a.conn().Queryx(query, args...)
 }
 
 func (a *SqlxAdapter) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
-	return a.db.QueryxContext(ctx, query, args...)
+	return a.conn().QueryxContext(ctx, query, args...)
 }
 
 func (a *SqlxAdapter) QueryRowx(query string, args ...interface{}) *sqlx.Row {
-	return a.db.QueryRowx(query, args...)
+	return a.conn().QueryRowx(query, args...)
 }
 
 func (a *SqlxAdapter) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
-	return a.db.QueryRowxContext(ctx, query, args...)
+	return a.conn().QueryRowxContext(ctx, query, args...)
 }
 
 func (a *SqlxAdapter) NamedExec(query string, arg interface{}) (sql.Result, error) {
-	return a.db.NamedExec(query, arg)
+	return a.conn().NamedExec(query, arg)
 }
 
 func (a *SqlxAdapter) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
-	return a.db.NamedExecContext(ctx, query, arg)
+	return a.conn().NamedExecContext(ctx, query, arg)
 }
 
 func (a *SqlxAdapter) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
-	return a.db.NamedQuery(query, arg)
+	return sqlx.NamedQuery(a.conn(), query, arg)
 }
 
 func (a *SqlxAdapter) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
-	return a.db.NamedQueryContext(ctx, query, arg)
+	return sqlx.NamedQueryContext(ctx, a.conn(), query, arg)
 }
 
 func (a *SqlxAdapter) Get(dest interface{}, query string, args ...interface{}) error {
-	return a.db.Get(dest, query, args...)
+	return a.conn().Get(dest, query, args...)
 }
 
 func (a *SqlxAdapter) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return a.db.GetContext(ctx, dest, query, args...)
+	return a.conn().GetContext(ctx, dest, query, args...)
 }
 
 func (a *SqlxAdapter) Select(dest interface{}, query string, args ...interface{}) error {
-	return a.db.Select(dest, query, args...)
+	return a.conn().Select(dest, query, args...)
 }
 
 func (a *SqlxAdapter) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return a.db.SelectContext(ctx, dest, query, args...)
+	return a.conn().SelectContext(ctx, dest, query, args...)
 }
 
 func (a *SqlxAdapter) GetDB() *sqlx.DB {
 	return a.db
 }
 
+// sqlxConn *sqlx.DB 与 *sqlx.Tx 的公共方法
+type sqlxConn interface {
+	sqlx.Ext
+	sqlx.ExtContext
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+	Get(dest interface{}, query string, args ...interface{}) error
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// conn 获取当前活跃的连接，事务中为 *sqlx.Tx
+func (a *SqlxAdapter) conn() sqlxConn {
+	if a.tx != nil {
+		return a.tx
+	}
+	return a.db
+}
+
 // ==================== SQLX 事务适配器 ====================
 
 // SqlxTxAdapter SQLX事务适配器 (保留原有设计兼容性)
@@ -437,11 +474,11 @@ func (a *SqlxTxAdapter) Rollback() error {
 }
 
 func (a *SqlxTxAdapter) Begin() (TransactionInterface, error) {
-	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, "cannot begin transaction within transaction")
+	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
 }
 
 func (a *SqlxTxAdapter) BeginTx(ctx context.Context, opts *sql.TxOptions) (TransactionInterface, error) {
-	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, "cannot begin transaction within transaction")
+	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
 }
 
 func (a *SqlxTxAdapter) Get(dest interface{}, query string, args ...interface{}) error {
@@ -641,7 +678,8 @@ func (a *GormAdapter) BatchUpdate(ctx context.Context, table string, data []map[
 			var whereClauses []string
 			var whereValues []interface{}
 
-			for col, val := range row {
+			for _, col := range sortedKeys(row) {
+				val := row[col]
 				isWhereColumn := false
 				for _, whereCol := range whereColumns {
 					if col == whereCol {
@@ -679,32 +717,35 @@ func (a *GormAdapter) BatchUpdate(ctx context.Context, table string, data []map[
 
 // 基本数据库操作
 func (a *GormAdapter) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return a.db.Raw(query, args...).Rows()
+	return a.getDB().Raw(query, args...).Rows()
 }
 
 func (a *GormAdapter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return a.db.WithContext(ctx).Raw(query, args...).Rows()
+	return a.getDB().WithContext(ctx).Raw(query, args...).Rows()
 }
 
 func (a *GormAdapter) QueryRow(query string, args ...interface{}) *sql.Row {
-	return a.db.Raw(query, args...).Row()
+	return a.getDB().Raw(query, args...).Row()
 }
 
 func (a *GormAdapter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return a.db.WithContext(ctx).Raw(query, args...).Row()
+	return a.getDB().WithContext(ctx).Raw(query, args...).Row()
 }
 
 func (a *GormAdapter) Exec(query string, args ...interface{}) (sql.Result, error) {
-	result := a.db.Exec(query, args...)
+	result := a.getDB().Exec(query, args...)
 	return &GormResult{result: result}, result.Error
 }
 
 func (a *GormAdapter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	result := a.db.WithContext(ctx).Exec(query, args...)
+	result := a.getDB().WithContext(ctx).Exec(query, args...)
 	return &GormResult{result: result}, result.Error
 }
 
 func (a *GormAdapter) Begin() (TransactionInterface, error) {
+	if a.tx != nil {
+		return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
+	}
 	tx := a.db.Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -713,7 +754,10 @@ func (a *GormAdapter) Begin() (TransactionInterface, error) {
 }
 
 func (a *GormAdapter) BeginTx(ctx context.Context, opts *sql.TxOptions) (TransactionInterface, error) {
-	tx := a.db.WithContext(ctx).Begin()
+	if a.tx != nil {
+		return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
+	}
+	tx := a.db.WithContext(ctx).Begin(opts)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -729,7 +773,7 @@ func (a *GormAdapter) PrepareContext(ctx context.Context, query string) (Stateme
 }
 
 func (a *GormAdapter) Ping() error {
-	sqlDB, err := a.db.DB()
+	sqlDB, err := a.getDB().DB()
 	if err != nil {
 		return err
 	}
@@ -737,7 +781,7 @@ func (a *GormAdapter) Ping() error {
 }
 
 func (a *GormAdapter) PingContext(ctx context.Context) error {
-	sqlDB, err := a.db.DB()
+	sqlDB, err := a.getDB().DB()
 	if err != nil {
 		return err
 	}
@@ -753,104 +797,110 @@ func (a *GormAdapter) Close() error {
 }
 
 func (a *GormAdapter) Commit() error {
-	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "cannot commit on non-transaction adapter")
+	if a.tx == nil {
+		return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "cannot commit on non-transaction adapter")
+	}
+	return a.tx.Commit().Error
 }
 
 func (a *GormAdapter) Rollback() error {
-	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "cannot rollback on non-transaction adapter")
+	if a.tx == nil {
+		return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "cannot rollback on non-transaction adapter")
+	}
+	return a.tx.Rollback().Error
 }
 
 func (a *GormAdapter) GetDB() *gorm.DB {
-	return a.db
+	return a.getDB()
 }
 
 // GORM特有方法
 func (a *GormAdapter) Model(value interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Model(value)}
+	return &GormAdapter{db: a.getDB().Model(value)}
 }
 
 func (a *GormAdapter) Table(name string, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Table(name, args...)}
+	return &GormAdapter{db: a.getDB().Table(name, args...)}
 }
 
 func (a *GormAdapter) Select(query interface{}, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Select(query, args...)}
+	return &GormAdapter{db: a.getDB().Select(query, args...)}
 }
 
 func (a *GormAdapter) Where(query interface{}, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Where(query, args...)}
+	return &GormAdapter{db: a.getDB().Where(query, args...)}
 }
 
 func (a *GormAdapter) Or(query interface{}, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Or(query, args...)}
+	return &GormAdapter{db: a.getDB().Or(query, args...)}
 }
 
 func (a *GormAdapter) Not(query interface{}, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Not(query, args...)}
+	return &GormAdapter{db: a.getDB().Not(query, args...)}
 }
 
 func (a *GormAdapter) Joins(query string, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Joins(query, args...)}
+	return &GormAdapter{db: a.getDB().Joins(query, args...)}
 }
 
 func (a *GormAdapter) Group(name string) *GormAdapter {
-	return &GormAdapter{db: a.db.Group(name)}
+	return &GormAdapter{db: a.getDB().Group(name)}
 }
 
 func (a *GormAdapter) Having(query interface{}, args ...interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Having(query, args...)}
+	return &GormAdapter{db: a.getDB().Having(query, args...)}
 }
 
 func (a *GormAdapter) Order(value interface{}) *GormAdapter {
-	return &GormAdapter{db: a.db.Order(value)}
+	return &GormAdapter{db: a.getDB().Order(value)}
 }
 
 func (a *GormAdapter) Limit(limit int) *GormAdapter {
-	return &GormAdapter{db: a.db.Limit(limit)}
+	return &GormAdapter{db: a.getDB().Limit(limit)}
 }
 
 func (a *GormAdapter) Offset(offset int) *GormAdapter {
-	return &GormAdapter{db: a.db.Offset(offset)}
+	return &GormAdapter{db: a.getDB().Offset(offset)}
 }
 
 func (a *GormAdapter) Create(value interface{}) error {
-	return a.db.Create(value).Error
+	return a.getDB().Create(value).Error
 }
 
 func (a *GormAdapter) Save(value interface{}) error {
-	return a.db.Save(value).Error
+	return a.getDB().Save(value).Error
 }
 
 func (a *GormAdapter) First(dest interface{}, conds ...interface{}) error {
-	return a.db.First(dest, conds...).Error
+	return a.getDB().First(dest, conds...).Error
 }
 
 func (a *GormAdapter) Find(dest interface{}, conds ...interface{}) error {
-	return a.db.Find(dest, conds...).Error
+	return a.getDB().Find(dest, conds...).Error
 }
 
 func (a *GormAdapter) Update(column string, value interface{}) error {
-	return a.db.Update(column, value).Error
+	return a.getDB().Update(column, value).Error
 }
 
 func (a *GormAdapter) Updates(values interface{}) error {
-	return a.db.Updates(values).Error
+	return a.getDB().Updates(values).Error
 }
 
 func (a *GormAdapter) Delete(value interface{}, conds ...interface{}) error {
-	return a.db.Delete(value, conds...).Error
+	return a.getDB().Delete(value, conds...).Error
 }
 
 func (a *GormAdapter) Count(count *int64) error {
-	return a.db.Count(count).Error
+	return a.getDB().Count(count).Error
 }
 
 func (a *GormAdapter) Scan(dest interface{}) error {
-	return a.db.Scan(dest).Error
+	return a.getDB().Scan(dest).Error
 }
 
 func (a *GormAdapter) Pluck(column string, dest interface{}) error {
-	return a.db.Pluck(column, dest).Error
+	return a.getDB().Pluck(column, dest).Error
 }
 
 // ==================== GORM 事务适配器 (兼容性) ====================
@@ -900,11 +950,11 @@ func (a *GormTxAdapterLegacy) ExecContext(ctx context.Context, query string, arg
 }
 
 func (a *GormTxAdapterLegacy) Begin() (TransactionInterface, error) {
-	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, "cannot begin transaction within transaction")
+	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
 }
 
 func (a *GormTxAdapterLegacy) BeginTx(ctx context.Context, opts *sql.TxOptions) (TransactionInterface, error) {
-	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, "cannot begin transaction within transaction")
+	return nil, errors.NewError(errors.ErrorCodeNestedTransaction, errors.MsgCannotBeginNestedTransaction)
 }
 
 func (a *GormTxAdapterLegacy) GetTx() *gorm.DB {
@@ -959,33 +1009,56 @@ func (a *MySQLDriverAdapter) QuoteString(str string) string {
 	return fmt.Sprintf("'%s'", strings.ReplaceAll(str, "'", "''"))
 }
 
+func (a *MySQLDriverAdapter) Placeholder(index int) string {
+	return "?"
+}
+
 func (a *MySQLDriverAdapter) BuildLimit(offset, limit int64) string {
+	if limit <= 0 {
+		if offset <= 0 {
+			return ""
+		}
+		// MySQL 不支持单独的 OFFSET，使用最大值作为 LIMIT
+		return fmt.Sprintf(" LIMIT %d, 18446744073709551615", offset)
+	}
 	if offset > 0 {
 		return fmt.Sprintf(" LIMIT %d, %d", offset, limit)
 	}
 	return fmt.Sprintf(" LIMIT %d", limit)
 }
 
+// BuildUpsert 单行 UPSERT，冲突时更新除冲突列外的全部列
 func (a *MySQLDriverAdapter) BuildUpsert(table string, data map[string]interface{}, conflictFields []string) (string, []interface{}) {
-	fields := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	updates := make([]string, 0, len(data))
-
-	for field, value := range data {
-		fields = append(fields, a.QuoteIdentifier(field))
-		placeholders = append(placeholders, "?")
-		values = append(values, value)
-		updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", a.QuoteIdentifier(field), a.QuoteIdentifier(field)))
+	sql, values, err := buildDriverUpsert(a, table, data, conflictFields)
+	if err != nil {
+		return "", nil
 	}
+	return sql, values
+}
 
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
-		table,
-		strings.Join(fields, ", "),
-		strings.Join(placeholders, ", "),
-		strings.Join(updates, ", "))
+// ValidateUpsert 检查 BuildUpsert 能否生成语句，返回其失败原因
+func (a *MySQLDriverAdapter) ValidateUpsert(table string, data map[string]interface{}, conflictFields []string) error {
+	_, _, err := buildDriverUpsert(a, table, data, conflictFields)
+	return err
+}
 
-	return sql, values
+// excluded 引用冲突行中待插入的值: VALUES(col)
+func (a *MySQLDriverAdapter) excluded(column string) string {
+	return fmt.Sprintf("VALUES(%s)", column)
+}
+
+// upsertStatement INSERT ... ON DUPLICATE KEY UPDATE，冲突由唯一索引判定，conflict 不参与生成
+// MySQL 无 DO NOTHING，assignments 为空时使用无副作用的自赋值代替 INSERT IGNORE (后者会吞掉其它错误)
+func (a *MySQLDriverAdapter) upsertStatement(table string, columns, rows, conflict, assignments []string) (string, error) {
+	if len(assignments) == 0 {
+		keep := columns[0]
+		if len(conflict) > 0 {
+			keep = conflict[0]
+		}
+		assignments = []string{fmt.Sprintf("%s = %s", keep, keep)}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
+		table, strings.Join(columns, ", "), strings.Join(rows, ", "), strings.Join(assignments, ", ")), nil
 }
 
 func (a *MySQLDriverAdapter) ConvertValue(value interface{}) (driver.Value, error) {
@@ -1036,45 +1109,48 @@ func (a *PostgreSQLDriverAdapter) QuoteString(str string) string {
 	return fmt.Sprintf("'%s'", strings.ReplaceAll(str, "'", "''"))
 }
 
+func (a *PostgreSQLDriverAdapter) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
 func (a *PostgreSQLDriverAdapter) BuildLimit(offset, limit int64) string {
+	if limit <= 0 {
+		if offset <= 0 {
+			return ""
+		}
+		return fmt.Sprintf(" OFFSET %d", offset)
+	}
 	if offset > 0 {
 		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
 	}
 	return fmt.Sprintf(" LIMIT %d", limit)
 }
 
+// BuildUpsert 单行 UPSERT，冲突时更新除冲突列外的全部列
+// 缺少冲突列时返回空语句，原因可通过 ValidateUpsert 获取
 func (a *PostgreSQLDriverAdapter) BuildUpsert(table string, data map[string]interface{}, conflictFields []string) (string, []interface{}) {
-	fields := make([]string, 0, len(data))
-	placeholders := make([]string, 0, len(data))
-	values := make([]interface{}, 0, len(data))
-	updates := make([]string, 0, len(data))
-
-	i := 1
-	for field, value := range data {
-		fields = append(fields, a.QuoteIdentifier(field))
-		placeholders = append(placeholders, fmt.Sprintf("$%d", i))
-		values = append(values, value)
-		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", a.QuoteIdentifier(field), a.QuoteIdentifier(field)))
-		i++
-	}
-
-	conflictClause := ""
-	if len(conflictFields) > 0 {
-		quotedFields := make([]string, len(conflictFields))
-		for i, field := range conflictFields {
-			quotedFields[i] = a.QuoteIdentifier(field)
-		}
-		conflictClause = fmt.Sprintf("(%s)", strings.Join(quotedFields, ", "))
+	sql, values, err := buildDriverUpsert(a, table, data, conflictFields)
+	if err != nil {
+		return "", nil
 	}
+	return sql, values
+}
 
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT %s DO UPDATE SET %s",
-		table,
-		strings.Join(fields, ", "),
-		strings.Join(placeholders, ", "),
-		conflictClause,
-		strings.Join(updates, ", "))
+// ValidateUpsert 检查 BuildUpsert 能否生成语句，返回其失败原因
+func (a *PostgreSQLDriverAdapter) ValidateUpsert(table string, data map[string]interface{}, conflictFields []string) error {
+	_, _, err := buildDriverUpsert(a, table, data, conflictFields)
+	return err
+}
 
-	return sql, values
+// excluded 引用冲突行中待插入的值: EXCLUDED.col
+func (a *PostgreSQLDriverAdapter) excluded(column string) string {
+	return "EXCLUDED." + column
+}
+
+// upsertStatement INSERT ... ON CONFLICT (conflict) DO UPDATE SET / DO NOTHING
+// DO UPDATE 必须指定冲突列
+func (a *PostgreSQLDriverAdapter) upsertStatement(table string, columns, rows, conflict, assignments []string) (string, error) {
+	return onConflictStatement(table, columns, rows, conflict, assignments)
 }
 
 func (a *PostgreSQLDriverAdapter) ConvertValue(value interface{}) (driver.Value, error) {
@@ -1093,6 +1169,268 @@ func (a *PostgreSQLDriverAdapter) RowsAffected(result sql.Result) (int64, error)
 	return result.RowsAffected()
 }
 
+// SQLiteDriverAdapter SQLite驱动适配器
+type SQLiteDriverAdapter struct{}
+
+func NewSQLiteDriverAdapter() *SQLiteDriverAdapter {
+	return &SQLiteDriverAdapter{}
+}
+
+func (a *SQLiteDriverAdapter) DriverName() string {
+	return "sqlite"
+}
+
+func (a *SQLiteDriverAdapter) SupportsFeature(feature string) bool {
+	supportedFeatures := map[string]bool{
+		"upsert":           true,
+		"returning":        true,
+		"json":             true,
+		"cte":              true,
+		"window_functions": true,
+		"full_text":        false,
+	}
+	return supportedFeatures[feature]
+}
+
+func (a *SQLiteDriverAdapter) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(identifier, `"`, `""`))
+}
+
+func (a *SQLiteDriverAdapter) QuoteString(str string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(str, "'", "''"))
+}
+
+func (a *SQLiteDriverAdapter) Placeholder(index int) string {
+	return "?"
+}
+
+func (a *SQLiteDriverAdapter) BuildLimit(offset, limit int64) string {
+	if limit <= 0 {
+		if offset <= 0 {
+			return ""
+		}
+		// SQLite 的 OFFSET 必须跟随 LIMIT，-1 表示不限制
+		return fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	}
+	if offset > 0 {
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
+
+// BuildUpsert 单行 UPSERT，冲突时更新除冲突列外的全部列
+// 缺少冲突列时返回空语句，原因可通过 ValidateUpsert 获取
+func (a *SQLiteDriverAdapter) BuildUpsert(table string, data map[string]interface{}, conflictFields []string) (string, []interface{}) {
+	sql, values, err := buildDriverUpsert(a, table, data, conflictFields)
+	if err != nil {
+		return "", nil
+	}
+	return sql, values
+}
+
+// ValidateUpsert 检查 BuildUpsert 能否生成语句，返回其失败原因
+func (a *SQLiteDriverAdapter) ValidateUpsert(table string, data map[string]interface{}, conflictFields []string) error {
+	_, _, err := buildDriverUpsert(a, table, data, conflictFields)
+	return err
+}
+
+// excluded 引用冲突行中待插入的值: EXCLUDED.col
+func (a *SQLiteDriverAdapter) excluded(column string) string {
+	return "EXCLUDED." + column
+}
+
+// upsertStatement INSERT ... ON CONFLICT (conflict) DO UPDATE SET / DO NOTHING
+// DO UPDATE 必须指定冲突列
+func (a *SQLiteDriverAdapter) upsertStatement(table string, columns, rows, conflict, assignments []string) (string, error) {
+	return onConflictStatement(table, columns, rows, conflict, assignments)
+}
+
+func (a *SQLiteDriverAdapter) ConvertValue(value interface{}) (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+func (a *SQLiteDriverAdapter) ConvertScanValue(src interface{}) (interface{}, error) {
+	return src, nil
+}
+
+func (a *SQLiteDriverAdapter) LastInsertId(result sql.Result) (int64, error) {
+	return result.LastInsertId()
+}
+
+func (a *SQLiteDriverAdapter) RowsAffected(result sql.Result) (int64, error) {
+	return result.RowsAffected()
+}
+
+// SQLServerDriverAdapter SQL Server驱动适配器
+type SQLServerDriverAdapter struct{}
+
+func NewSQLServerDriverAdapter() *SQLServerDriverAdapter {
+	return &SQLServerDriverAdapter{}
+}
+
+func (a *SQLServerDriverAdapter) DriverName() string {
+	return "sqlserver"
+}
+
+func (a *SQLServerDriverAdapter) SupportsFeature(feature string) bool {
+	supportedFeatures := map[string]bool{
+		"upsert":           true,
+		"returning":        true, // OUTPUT INSERTED.* / DELETED.*
+		"json":             true,
+		"cte":              true,
+		"window_functions": true,
+		"full_text":        true,
+	}
+	return supportedFeatures[feature]
+}
+
+func (a *SQLServerDriverAdapter) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("[%s]", strings.ReplaceAll(identifier, "]", "]]"))
+}
+
+func (a *SQLServerDriverAdapter) QuoteString(str string) string {
+	return fmt.Sprintf("N'%s'", strings.ReplaceAll(str, "'", "''"))
+}
+
+func (a *SQLServerDriverAdapter) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
+
+// BuildLimit SQL Server 使用 OFFSET ... FETCH 分页，调用方需保证存在 ORDER BY
+func (a *SQLServerDriverAdapter) BuildLimit(offset, limit int64) string {
+	if limit <= 0 {
+		if offset <= 0 {
+			return ""
+		}
+		return fmt.Sprintf(" OFFSET %d ROWS", offset)
+	}
+	return fmt.Sprintf(" OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+}
+
+// BuildUpsert 单行 MERGE，冲突时更新除冲突列外的全部列
+// 缺少冲突列时返回空语句，原因可通过 ValidateUpsert 获取
+func (a *SQLServerDriverAdapter) BuildUpsert(table string, data map[string]interface{}, conflictFields []string) (string, []interface{}) {
+	sql, values, err := buildDriverUpsert(a, table, data, conflictFields)
+	if err != nil {
+		return "", nil
+	}
+	return sql + ";", values
+}
+
+// ValidateUpsert 检查 BuildUpsert 能否生成语句，返回其失败原因
+func (a *SQLServerDriverAdapter) ValidateUpsert(table string, data map[string]interface{}, conflictFields []string) error {
+	_, _, err := buildDriverUpsert(a, table, data, conflictFields)
+	return err
+}
+
+// excluded 引用冲突行中待插入的值: source.col
+func (a *SQLServerDriverAdapter) excluded(column string) string {
+	return "source." + column
+}
+
+// upsertStatement MERGE INTO ... USING (VALUES ...) AS source ON 冲突列匹配 (不含结尾的分号，便于追加 OUTPUT)
+// assignments 的目标列加 target. 前缀；必须指定冲突列
+func (a *SQLServerDriverAdapter) upsertStatement(table string, columns, rows, conflict, assignments []string) (string, error) {
+	if len(conflict) == 0 {
+		return "", errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgUpsertConflictRequired)
+	}
+	matches := make([]string, len(conflict))
+	for i, col := range conflict {
+		matches[i] = fmt.Sprintf("target.%s = source.%s", col, col)
+	}
+	sources := make([]string, len(columns))
+	for i, col := range columns {
+		sources[i] = "source." + col
+	}
+
+	var sql strings.Builder
+	sql.WriteString(fmt.Sprintf("MERGE INTO %s AS target USING (VALUES %s) AS source (%s) ON %s",
+		table, strings.Join(rows, ", "), strings.Join(columns, ", "), strings.Join(matches, " AND ")))
+	if len(assignments) > 0 {
+		updates := make([]string, len(assignments))
+		for i, assignment := range assignments {
+			updates[i] = "target." + assignment
+		}
+		sql.WriteString(" WHEN MATCHED THEN UPDATE SET " + strings.Join(updates, ", "))
+	}
+	sql.WriteString(fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		strings.Join(columns, ", "), strings.Join(sources, ", ")))
+	return sql.String(), nil
+}
+
+// upsertAdapter 可生成单行 UPSERT 的驱动适配器
+type upsertAdapter interface {
+	upsertDialect
+	QuoteIdentifier(identifier string) string
+	Placeholder(index int) string
+}
+
+// buildDriverUpsert 各驱动适配器 BuildUpsert 共用的单行 UPSERT 生成，冲突时更新除冲突列外的全部列
+func buildDriverUpsert(a upsertAdapter, table string, data map[string]interface{}, conflictFields []string) (string, []interface{}, error) {
+	columns := sortedKeys(data)
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	assignments := make([]string, 0, len(columns))
+	values := make([]interface{}, len(columns))
+	conflicts := make(map[string]bool, len(conflictFields))
+	for _, field := range conflictFields {
+		conflicts[field] = true
+	}
+	for i, field := range columns {
+		quoted[i] = a.QuoteIdentifier(field)
+		placeholders[i] = a.Placeholder(i + 1)
+		values[i] = data[field]
+		if !conflicts[field] {
+			assignments = append(assignments, fmt.Sprintf("%s = %s", quoted[i], a.excluded(quoted[i])))
+		}
+	}
+	conflict := make([]string, len(conflictFields))
+	for i, field := range conflictFields {
+		conflict[i] = a.QuoteIdentifier(field)
+	}
+
+	sql, err := a.upsertStatement(table, quoted, []string{"(" + strings.Join(placeholders, ", ") + ")"}, conflict, assignments)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, values, nil
+}
+
+// onConflictStatement PostgreSQL / SQLite 共用的 INSERT ... ON CONFLICT 语句
+func onConflictStatement(table string, columns, rows, conflict, assignments []string) (string, error) {
+	var sql strings.Builder
+	sql.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT",
+		table, strings.Join(columns, ", "), strings.Join(rows, ", ")))
+	if len(conflict) > 0 {
+		sql.WriteString(fmt.Sprintf(" (%s)", strings.Join(conflict, ", ")))
+	}
+	if len(assignments) == 0 {
+		sql.WriteString(" DO NOTHING")
+		return sql.String(), nil
+	}
+	if len(conflict) == 0 {
+		return "", errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgUpsertConflictRequired)
+	}
+	sql.WriteString(" DO UPDATE SET " + strings.Join(assignments, ", "))
+	return sql.String(), nil
+}
+
+func (a *SQLServerDriverAdapter) ConvertValue(value interface{}) (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+func (a *SQLServerDriverAdapter) ConvertScanValue(src interface{}) (interface{}, error) {
+	return src, nil
+}
+
+func (a *SQLServerDriverAdapter) LastInsertId(result sql.Result) (int64, error) {
+	return 0, errors.NewError(errors.ErrorCodeDBError, errors.MsgSQLServerNotSupportLastInsertId)
+}
+
+func (a *SQLServerDriverAdapter) RowsAffected(result sql.Result) (int64, error) {
+	return result.RowsAffected()
+}
+
 // ==================== 适配器工厂 ====================
 
 // AdapterFactory 适配器工厂
@@ -1115,6 +1453,14 @@ func NewAdapterFactory() *AdapterFactory {
 		return NewPostgreSQLDriverAdapter()
 	})
 
+	factory.Register("sqlite", func() DriverAdapterInterface {
+		return NewSQLiteDriverAdapter()
+	})
+
+	factory.Register("sqlserver", func() DriverAdapterInterface {
+		return NewSQLServerDriverAdapter()
+	})
+
 	return factory
 }
 
@@ -1293,7 +1639,8 @@ func (w *DatabaseAdapterWrapper) BatchInsert(ctx context.Context, table string,
 		var placeholders []string
 		var values []interface{}
 
-		for col, val := range row {
+		for _, col := range sortedKeys(row) {
+			val := row[col]
 			columns = append(columns, col)
 			placeholders = append(placeholders, "?")
 			values = append(values, val)
@@ -1325,7 +1672,8 @@ func (w *DatabaseAdapterWrapper) BatchUpdate(ctx context.Context, table string,
 		var whereClauses []string
 		var whereValues []interface{}
 
-		for col, val := range row {
+		for _, col := range sortedKeys(row) {
+			val := row[col]
 			isWhereColumn := false
 			for _, whereCol := range whereColumns {
 				if col == whereCol {
@@ -1371,6 +1719,8 @@ func NewUniversalAdapter(instance interface{}) (UniversalAdapterInterface, error
 		return NewSqlxTxAdapter(db), nil
 	case *gorm.DB:
 		return NewGormAdapter(db), nil
+	case UniversalAdapterInterface:
+		return db, nil
 	default:
 		return nil, errors.NewErrorf(errors.ErrorCodeUnsupported, "unsupported database instance type: %T", instance)
 	}
@@ -166,3 +166,11 @@ const (
 	QP_ORLT = "orlt" // OR LT
 	QP_ORGT = "orgt" // OR GT
 )
+
+// 集合、范围与空值操作符 (查询字符串扩展，见 query.ParseURLValues)
+const (
+	QP_IN   = "in"   // IN，多个值以逗号分隔
+	QP_NIN  = "nin"  // NOT IN
+	QP_BTW  = "btw"  // BETWEEN，形如 min,max
+	QP_NULL = "null" // true 为 IS NULL，false 为 IS NOT NULL
+)
@@ -15,12 +15,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
 	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/txctx"
+	"gorm.io/gorm"
 )
 
 // Builder 通用SQL查询构建器 (并发安全)
@@ -32,6 +36,21 @@ type Builder struct {
 	ctx     context.Context
 	timeout time.Duration
 
+	// 方言: 占位符与标识符引用
+	driver     DriverAdapterInterface
+	quoteIdent bool
+
+	// 不可变模式: 链式调用返回副本
+	immutable bool
+
+	// 安全模式: 标识符/操作符校验器及第一个校验错误
+	safeMode  bool
+	validator *column.Validator
+	err       error
+
+	// 事务嵌套深度: 0 表示不在事务中，大于 1 时处于保存点内
+	txDepth int
+
 	// SQL构建组件
 	table       string
 	tableAlias  string
@@ -46,13 +65,48 @@ type Builder struct {
 	offsetVal   int64
 
 	// 操作数据
-	insertData  map[string]interface{}
-	updateData  map[string]interface{}
-	deleteWhere bool
-
-	// 参数
-	args      []interface{}
-	queryType string // select, insert, update, delete
+	insertData    map[string]interface{}
+	insertColumns []string // 调用方指定的列顺序，为空时按列名排序
+	updateData    map[string]interface{}
+	updateColumns []string // 调用方指定的列顺序，为空时按列名排序
+	deleteWhere   bool
+
+	// UPSERT: 多行数据、冲突列、冲突时更新的列与自定义表达式
+	upsertRows     []map[string]interface{}
+	upsertConflict []string
+	upsertUpdate   []string
+	upsertSets     []upsertSet
+	upsertNothing  bool
+
+	// 写操作返回的列 (RETURNING / OUTPUT) 与回退回查使用的主键列
+	returningCols []string
+	returningKey  string
+
+	// 悲观锁: 锁模式、等待策略与锁定的表
+	lockMode string
+	lockWait string
+	lockOf   []string
+
+	// 游标分页: 排序列、游标与方向
+	keysetCols   []keysetColumn
+	keysetCursor string
+	keysetBefore bool
+
+	// 子查询、联合查询与公用表表达式
+	fromSub *Builder
+	unions  []unionClause
+	ctes    []cteClause
+
+	// 窗口函数列与命名窗口
+	windowExprs  []*WindowExpr
+	namedWindows []namedWindow
+
+	// 参数 (按子句分别存放，生成SQL时按出现顺序合并)
+	selectArgs []interface{}
+	joinArgs   []interface{}
+	args       []interface{} // WHERE 参数
+	havingArgs []interface{}
+	queryType  string // select, insert, update, delete, upsert
 }
 
 // New 创建新的查询构建器
@@ -62,10 +116,14 @@ func New(dbInstance interface{}) (*Builder, error) {
 		return nil, err
 	}
 
+	driver := resolveDriverAdapter(adapter.GetDialect())
+
 	return &Builder{
 		adapter:     adapter,
 		ctx:         context.Background(),
 		timeout:     30 * time.Second,
+		driver:      driver,
+		quoteIdent:  driver != nil,
 		columns:     []string{},
 		joins:       []string{},
 		wheres:      []string{},
@@ -80,15 +138,45 @@ func New(dbInstance interface{}) (*Builder, error) {
 }
 
 // WithContext 设置上下文 (并发安全)
+// ctx 携带 txctx 事务且构建器尚未处于事务中时，返回在该事务上执行的副本，原构建器不受影响
+// 仅基于同一个 *sql.DB 的 GORM 构建器可加入该事务，否则副本记录错误，由 Err() 和执行方法返回
 func (b *Builder) WithContext(ctx context.Context) *Builder {
+	tx, joinTx := txctx.From(ctx)
+	joinTx = joinTx && b.txDepth == 0
+	if joinTx {
+		b = b.Clone()
+	} else {
+		b = b.derive()
+	}
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.ctx = ctx
+	if joinTx {
+		if err := b.joinableTx(tx); err != nil {
+			b.reject(err)
+			return b
+		}
+		b.adapter = NewGormTxAdapter(tx)
+		b.txDepth = 1
+	}
 	return b
 }
 
+// joinableTx 检查构建器能否加入 context 中的 GORM 事务
+func (b *Builder) joinableTx(tx *gorm.DB) error {
+	gormDB, ok := b.adapter.GetInstance().(*gorm.DB)
+	if !ok {
+		return errors.NewErrorf(errors.ErrorCodeAdapterNotSupported, errors.MsgTxContextMismatch, "builder is not backed by GORM")
+	}
+	if !txctx.SameDB(tx, gormDB) {
+		return errors.NewErrorf(errors.ErrorCodeAdapterNotSupported, errors.MsgTxContextMismatch, "transaction belongs to a different database")
+	}
+	return nil
+}
+
 // WithTimeout 设置超时时间 (并发安全)
 func (b *Builder) WithTimeout(timeout time.Duration) *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.timeout = timeout
@@ -99,17 +187,27 @@ func (b *Builder) WithTimeout(timeout time.Duration) *Builder {
 
 // Table 设置表名 (并发安全)
 func (b *Builder) Table(table string) *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	if err := b.validateTable(table); err != nil {
+		b.reject(err)
+		return b
+	}
 	b.table = table
+	b.fromSub = nil
 	b.queryType = "select"
 	return b
 }
 
 // As 设置表别名 (并发安全)
 func (b *Builder) As(alias string) *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	if !b.checkTables(alias) {
+		return b
+	}
 	b.tableAlias = alias
 	return b
 }
@@ -118,9 +216,13 @@ func (b *Builder) As(alias string) *Builder {
 
 // Select 选择列 (并发安全)
 func (b *Builder) Select(columns ...string) *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.queryType = "select"
+	if !b.checkColumns(columns...) {
+		return b
+	}
 	if len(columns) == 0 {
 		b.columns = []string{"*"}
 	} else {
@@ -133,16 +235,18 @@ func (b *Builder) Select(columns ...string) *Builder {
 
 // SelectRaw 选择原始SQL (并发安全)
 func (b *Builder) SelectRaw(sql string, args ...interface{}) *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.queryType = "select"
 	b.columns = []string{sql}
-	b.args = append(b.args, args...)
+	b.selectArgs = append([]interface{}{}, args...)
 	return b
 }
 
 // Distinct 去重 (并发安全)
 func (b *Builder) Distinct() *Builder {
+	b = b.derive()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	b.distinct = true
@@ -173,13 +277,15 @@ func (b *Builder) FullJoin(table, on string, args ...interface{}) *Builder {
 
 // CrossJoin 交叉连接
 func (b *Builder) CrossJoin(table string) *Builder {
+	b = b.derive()
 	b.joins = append(b.joins, fmt.Sprintf("CROSS JOIN %s", table))
 	return b
 }
 
 func (b *Builder) addJoin(joinType, table, on string, args ...interface{}) *Builder {
+	b = b.derive()
 	b.joins = append(b.joins, fmt.Sprintf("%s JOIN %s ON %s", joinType, table, on))
-	b.args = append(b.args, args...)
+	b.joinArgs = append(b.joinArgs, args...)
 	return b
 }
 
@@ -197,24 +303,14 @@ func (b *Builder) OrWhere(column string, operator string, value interface{}) *Bu
 
 // WhereRaw 原始WHERE
 func (b *Builder) WhereRaw(sql string, args ...interface{}) *Builder {
-	if len(b.wheres) > 0 {
-		b.wheres = append(b.wheres, fmt.Sprintf("AND %s", sql))
-	} else {
-		b.wheres = append(b.wheres, sql)
-	}
-	b.args = append(b.args, args...)
-	return b
+	b = b.derive()
+	return b.appendWhere("AND", sql, args...)
 }
 
 // OrWhereRaw 原始OR WHERE
 func (b *Builder) OrWhereRaw(sql string, args ...interface{}) *Builder {
-	if len(b.wheres) > 0 {
-		b.wheres = append(b.wheres, fmt.Sprintf("OR %s", sql))
-	} else {
-		b.wheres = append(b.wheres, sql)
-	}
-	b.args = append(b.args, args...)
-	return b
+	b = b.derive()
+	return b.appendWhere("OR", sql, args...)
 }
 
 // WhereIn IN条件
@@ -222,10 +318,14 @@ func (b *Builder) WhereIn(column string, values ...interface{}) *Builder {
 	if len(values) == 0 {
 		return b
 	}
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
 	placeholders := strings.Repeat("?,", len(values))
 	placeholders = placeholders[:len(placeholders)-1]
-	sql := fmt.Sprintf("%s IN (%s)", column, placeholders)
-	return b.WhereRaw(sql, values...)
+	sql := fmt.Sprintf("%s IN (%s)", b.quoteIdentifier(column), placeholders)
+	return b.appendWhere("AND", sql, values...)
 }
 
 // WhereNotIn NOT IN条件
@@ -233,25 +333,41 @@ func (b *Builder) WhereNotIn(column string, values ...interface{}) *Builder {
 	if len(values) == 0 {
 		return b
 	}
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
 	placeholders := strings.Repeat("?,", len(values))
 	placeholders = placeholders[:len(placeholders)-1]
-	sql := fmt.Sprintf("%s NOT IN (%s)", column, placeholders)
-	return b.WhereRaw(sql, values...)
+	sql := fmt.Sprintf("%s NOT IN (%s)", b.quoteIdentifier(column), placeholders)
+	return b.appendWhere("AND", sql, values...)
 }
 
 // WhereBetween BETWEEN条件
 func (b *Builder) WhereBetween(column string, min, max interface{}) *Builder {
-	return b.WhereRaw(fmt.Sprintf("%s BETWEEN ? AND ?", column), min, max)
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	return b.appendWhere("AND", fmt.Sprintf("%s BETWEEN ? AND ?", b.quoteIdentifier(column)), min, max)
 }
 
 // WhereNull NULL条件
 func (b *Builder) WhereNull(column string) *Builder {
-	return b.WhereRaw(fmt.Sprintf("%s IS NULL", column))
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	return b.appendWhere("AND", fmt.Sprintf("%s IS NULL", b.quoteIdentifier(column)))
 }
 
 // WhereNotNull NOT NULL条件
 func (b *Builder) WhereNotNull(column string) *Builder {
-	return b.WhereRaw(fmt.Sprintf("%s IS NOT NULL", column))
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	return b.appendWhere("AND", fmt.Sprintf("%s IS NOT NULL", b.quoteIdentifier(column)))
 }
 
 // WhereLike LIKE条件
@@ -260,12 +376,22 @@ func (b *Builder) WhereLike(column, value string) *Builder {
 }
 
 func (b *Builder) addWhere(boolean string, column, operator string, value interface{}) *Builder {
+	b = b.derive()
+	operator, ok := b.checkOperator(operator)
+	if !ok || !b.checkColumns(column) {
+		return b
+	}
+	return b.appendWhere(boolean, fmt.Sprintf("%s %s ?", b.quoteIdentifier(column), operator), value)
+}
+
+// appendWhere 以 AND/OR 追加条件片段，首个条件不带连接符
+func (b *Builder) appendWhere(boolean string, sql string, args ...interface{}) *Builder {
 	if len(b.wheres) > 0 {
-		b.wheres = append(b.wheres, fmt.Sprintf("%s %s %s ?", boolean, column, operator))
+		b.wheres = append(b.wheres, fmt.Sprintf("%s %s", boolean, sql))
 	} else {
-		b.wheres = append(b.wheres, fmt.Sprintf("%s %s ?", column, operator))
+		b.wheres = append(b.wheres, sql)
 	}
-	b.args = append(b.args, value)
+	b.args = append(b.args, args...)
 	return b
 }
 
@@ -273,21 +399,31 @@ func (b *Builder) addWhere(boolean string, column, operator string, value interf
 
 // GroupBy 分组
 func (b *Builder) GroupBy(columns ...string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(columns...) {
+		return b
+	}
 	b.groupByCols = append(b.groupByCols, columns...)
 	return b
 }
 
 // Having HAVING条件
 func (b *Builder) Having(column, operator string, value interface{}) *Builder {
-	b.havings = append(b.havings, fmt.Sprintf("%s %s ?", column, operator))
-	b.args = append(b.args, value)
+	b = b.derive()
+	operator, ok := b.checkOperator(operator)
+	if !ok || !b.checkColumns(column) {
+		return b
+	}
+	b.havings = append(b.havings, fmt.Sprintf("%s %s ?", b.quoteIdentifier(column), operator))
+	b.havingArgs = append(b.havingArgs, value)
 	return b
 }
 
 // HavingRaw 原始HAVING
 func (b *Builder) HavingRaw(sql string, args ...interface{}) *Builder {
+	b = b.derive()
 	b.havings = append(b.havings, sql)
-	b.args = append(b.args, args...)
+	b.havingArgs = append(b.havingArgs, args...)
 	return b
 }
 
@@ -295,18 +431,27 @@ func (b *Builder) HavingRaw(sql string, args ...interface{}) *Builder {
 
 // OrderBy 排序（升序）
 func (b *Builder) OrderBy(column string) *Builder {
-	b.orderByCols = append(b.orderByCols, fmt.Sprintf("%s ASC", column))
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.orderByCols = append(b.orderByCols, fmt.Sprintf("%s ASC", b.quoteIdentifier(column)))
 	return b
 }
 
 // OrderByDesc 排序（降序）
 func (b *Builder) OrderByDesc(column string) *Builder {
-	b.orderByCols = append(b.orderByCols, fmt.Sprintf("%s DESC", column))
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.orderByCols = append(b.orderByCols, fmt.Sprintf("%s DESC", b.quoteIdentifier(column)))
 	return b
 }
 
 // OrderByRaw 原始ORDER BY
 func (b *Builder) OrderByRaw(sql string) *Builder {
+	b = b.derive()
 	b.orderByCols = append(b.orderByCols, sql)
 	return b
 }
@@ -315,18 +460,21 @@ func (b *Builder) OrderByRaw(sql string) *Builder {
 
 // Limit 限制结果数
 func (b *Builder) Limit(limit int64) *Builder {
+	b = b.derive()
 	b.limitVal = limit
 	return b
 }
 
 // Offset 偏移
 func (b *Builder) Offset(offset int64) *Builder {
+	b = b.derive()
 	b.offsetVal = offset
 	return b
 }
 
 // Paginate 分页
 func (b *Builder) Paginate(page, pageSize int64) *Builder {
+	b = b.derive()
 	if page < 1 {
 		page = 1
 	}
@@ -340,18 +488,52 @@ func (b *Builder) Paginate(page, pageSize int64) *Builder {
 
 // ==================== INSERT ====================
 
-// Insert 插入
+// Insert 插入 (列按名称排序，保证SQL与参数顺序稳定)
 func (b *Builder) Insert(data map[string]interface{}) *Builder {
+	b = b.derive()
+	for col := range data {
+		if !b.checkColumns(col) {
+			return b
+		}
+	}
 	b.queryType = "insert"
 	b.insertData = data
+	b.insertColumns = nil
+	return b
+}
+
+// InsertColumns 按指定列顺序插入，cols 与 values 一一对应
+func (b *Builder) InsertColumns(cols []string, values []interface{}) *Builder {
+	b = b.derive()
+	if !b.checkColumns(cols...) {
+		return b
+	}
+	b.queryType = "insert"
+	b.insertData = make(map[string]interface{}, len(cols))
+	b.insertColumns = make([]string, 0, len(cols))
+	for i, col := range cols {
+		if i >= len(values) {
+			break
+		}
+		if _, exists := b.insertData[col]; !exists {
+			b.insertColumns = append(b.insertColumns, col)
+		}
+		b.insertData[col] = values[i]
+	}
 	return b
 }
 
 // InsertGetID 插入并返回ID
+// 支持 RETURNING / OUTPUT 的方言直接返回 id 列，其余使用 LastInsertId
 func (b *Builder) InsertGetID(data map[string]interface{}) (int64, error) {
-	b.queryType = "insert"
-	b.insertData = data
-	result, err := b.Exec()
+	if b.supportsReturning() {
+		// 在副本上追加 RETURNING，构建器复用时不会累积返回列
+		var id int64
+		err := b.Clone().Insert(data).Returning(constant.FieldID).ExecReturning(&id)
+		return id, err
+	}
+
+	result, err := b.Insert(data).Exec()
 	if err != nil {
 		return 0, err
 	}
@@ -360,42 +542,67 @@ func (b *Builder) InsertGetID(data map[string]interface{}) (int64, error) {
 
 // ==================== UPDATE ====================
 
-// Update 更新
+// Update 更新 (列按名称排序，保证SQL与参数顺序稳定)
 func (b *Builder) Update(data map[string]interface{}) (*Builder, error) {
+	b = b.derive()
+	for col := range data {
+		if !b.checkColumns(col) {
+			return b, b.err
+		}
+	}
 	b.queryType = "update"
 	b.updateData = data
+	b.updateColumns = nil
 	return b, nil
 }
 
-// Set 单个字段更新
+// UpdateColumns 按指定列顺序更新，cols 与 values 一一对应
+func (b *Builder) UpdateColumns(cols []string, values []interface{}) *Builder {
+	b = b.derive()
+	b.queryType = "update"
+	b.updateData = make(map[string]interface{}, len(cols))
+	b.updateColumns = nil
+	for i, col := range cols {
+		if i >= len(values) {
+			break
+		}
+		b = b.Set(col, values[i])
+	}
+	return b
+}
+
+// Set 单个字段更新 (按调用顺序排列)
 func (b *Builder) Set(column string, value interface{}) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
 	b.queryType = "update"
 	if b.updateData == nil {
 		b.updateData = make(map[string]interface{})
 	}
+	if _, exists := b.updateData[column]; !exists {
+		b.updateColumns = append(b.updateColumns, column)
+	}
 	b.updateData[column] = value
 	return b
 }
 
+// incrementExpr 自增/自减表达式，渲染为 col = col +/- ?
+type incrementExpr struct {
+	op    string
+	value int64
+}
+
 // Increment 增加
 func (b *Builder) Increment(column string, value int64) error {
-	b.queryType = "update"
-	b.updateData[column] = struct {
-		Op    string
-		Value int64
-	}{Op: "+", Value: value}
-	_, err := b.Exec()
+	_, err := b.Set(column, incrementExpr{op: "+", value: value}).Exec()
 	return err
 }
 
 // Decrement 减少
 func (b *Builder) Decrement(column string, value int64) error {
-	b.queryType = "update"
-	b.updateData[column] = struct {
-		Op    string
-		Value int64
-	}{Op: "-", Value: value}
-	_, err := b.Exec()
+	_, err := b.Set(column, incrementExpr{op: "-", value: value}).Exec()
 	return err
 }
 
@@ -403,6 +610,7 @@ func (b *Builder) Decrement(column string, value int64) error {
 
 // Delete 删除
 func (b *Builder) Delete() *Builder {
+	b = b.derive()
 	b.queryType = "delete"
 	b.deleteWhere = true
 	return b
@@ -410,34 +618,67 @@ func (b *Builder) Delete() *Builder {
 
 // ==================== 执行方法 ====================
 
-// ToSQL 生成SQL
+// ToSQL 生成SQL (可重复调用，不修改构建器状态)
 func (b *Builder) ToSQL() (string, []interface{}) {
-	var sql strings.Builder
+	sql, args := b.build()
+	return b.rebindPlaceholders(sql), args
+}
 
+// build 生成使用 ? 占位符的SQL，参数按其在SQL中出现的顺序排列
+// 子查询通过 build 嵌入外层查询，由外层统一改写占位符
+func (b *Builder) build() (string, []interface{}) {
+	withSQL, withArgs := b.buildWith()
+	if withSQL == "" {
+		return b.buildStatement()
+	}
+	sql, args := b.buildStatement()
+	return withSQL + sql, append(withArgs, args...)
+}
+
+// buildStatement 生成主语句 (不含 WITH 子句)
+func (b *Builder) buildStatement() (string, []interface{}) {
 	switch b.queryType {
-	case "select":
-		sql.WriteString(b.buildSelect())
 	case "insert":
-		sql.WriteString(b.buildInsert())
+		return b.buildInsert()
+	case "upsert":
+		return b.buildUpsert()
 	case "update":
-		sql.WriteString(b.buildUpdate())
+		sql, setArgs := b.buildUpdate()
+		// SET 参数在 WHERE 参数之前
+		return sql, append(setArgs, b.args...)
 	case "delete":
-		sql.WriteString(b.buildDelete())
+		return b.buildDelete(), b.args
+	case "select":
+		return b.buildSelect()
+	default:
+		return "", b.args
 	}
-
-	return sql.String(), b.args
 }
 
 // First 获取第一条记录
+// dest 支持 *struct / **struct / *map[string]interface{} / *标量，无记录时返回 sql.ErrNoRows
 func (b *Builder) First(dest interface{}) error {
-	b.Limit(1)
-	sql, args := b.ToSQL()
-	row := b.adapter.QueryRowContext(b.ctx, sql, args...)
-	return row.Scan(dest)
+	if b.err != nil {
+		return b.err
+	}
+	query := b.Clone()
+	query.limitVal = 1
+	sql, args := query.ToSQL()
+	rows, err := b.adapter.QueryContext(b.ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanOne(rows, dest)
 }
 
 // Get 获取结果集
+// dest 支持 *[]struct / *[]*struct / *[]map[string]interface{} / *[]标量，按列名映射 db/gorm/json 标签
 func (b *Builder) Get(dest interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
 	sql, args := b.ToSQL()
 	rows, err := b.adapter.QueryContext(b.ctx, sql, args...)
 	if err != nil {
@@ -445,64 +686,48 @@ func (b *Builder) Get(dest interface{}) error {
 	}
 	defer rows.Close()
 
-	// 使用反射处理扫描
-	destVal := reflect.ValueOf(dest)
-	if destVal.Kind() != reflect.Ptr {
-		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
-	}
-
-	sliceVal := destVal.Elem()
-	if sliceVal.Kind() != reflect.Slice {
-		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
-	}
-
-	elemType := sliceVal.Type().Elem()
-
-	for rows.Next() {
-		elem := reflect.New(elemType).Elem()
-
-		// 扫描行到结构体
-		fields := make([]interface{}, 0)
-		for i := 0; i < elem.NumField(); i++ {
-			fields = append(fields, elem.Field(i).Addr().Interface())
-		}
-
-		if err := rows.Scan(fields...); err != nil {
-			return err
-		}
-
-		sliceVal.Set(reflect.Append(sliceVal, elem))
-	}
-
-	return rows.Err()
+	return scanAll(rows, dest)
 }
 
 // Exec 执行SQL
 func (b *Builder) Exec() (sql.Result, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
 	sql, args := b.ToSQL()
 	return b.adapter.ExecContext(b.ctx, sql, args...)
 }
 
-// Count 获取计数
-func (b *Builder) Count() (int64, error) {
-	oldCols := b.columns
-	oldLimit := b.limitVal
-	oldOffset := b.offsetVal
-
-	b.columns = []string{"COUNT(*) as count"}
-	b.limitVal = 0
-	b.offsetVal = 0
+// Count 获取计数，可指定列: Count("email") 统计非空值，DISTINCT 查询时统计去重值
+// 忽略 ORDER BY / LIMIT；存在 GROUP BY / UNION 或对 DISTINCT 结果计数时统计子查询的行数
+// 在副本上生成计数查询，不修改当前构建器
+func (b *Builder) Count(column ...string) (int64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(column) > 0 && column[0] != "" {
+		if err := b.validateColumn(column[0]); err != nil {
+			return 0, err
+		}
+	}
+	derived := b.needsDerivedAggregate()
+	expr := "COUNT(*)"
+	if len(column) > 0 && column[0] != "" {
+		col := b.aggregateColumn(column[0], derived)
+		if b.distinct {
+			expr = fmt.Sprintf("COUNT(DISTINCT %s)", col)
+		} else {
+			expr = fmt.Sprintf("COUNT(%s)", col)
+		}
+	} else if b.distinct {
+		derived = true
+	}
 
-	sql, args := b.ToSQL()
+	sql, args := b.aggregateQuery(expr, derived).ToSQL()
 	row := b.adapter.QueryRowContext(b.ctx, sql, args...)
 
 	var count int64
 	err := row.Scan(&count)
-
-	b.columns = oldCols
-	b.limitVal = oldLimit
-	b.offsetVal = oldOffset
-
 	return count, err
 }
 
@@ -519,6 +744,9 @@ func (b *Builder) BatchInsert(data []map[string]interface{}) error {
 	if len(data) == 0 {
 		return nil
 	}
+	if b.err != nil {
+		return b.err
+	}
 	return b.adapter.BatchInsert(b.ctx, b.table, data)
 }
 
@@ -527,32 +755,10 @@ func (b *Builder) BatchUpdate(data []map[string]interface{}, whereColumns []stri
 	if len(data) == 0 {
 		return nil
 	}
-	return b.adapter.BatchUpdate(b.ctx, b.table, data, whereColumns)
-}
-
-// ==================== 事务支持 ====================
-
-// Transaction 事务
-func (b *Builder) Transaction(fn func(*Builder) error) error {
-	tx, err := b.adapter.BeginTx(b.ctx, nil)
-	if err != nil {
-		return err
+	if b.err != nil {
+		return b.err
 	}
-
-	txBuilder := &Builder{
-		adapter: tx.(UniversalAdapterInterface),
-		ctx:     b.ctx,
-		timeout: b.timeout,
-		table:   b.table,
-		columns: b.columns,
-	}
-
-	if err := fn(txBuilder); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	return tx.Commit()
+	return b.adapter.BatchUpdate(b.ctx, b.table, data, whereColumns)
 }
 
 // ==================== 工具方法 ====================
@@ -576,8 +782,9 @@ func (b *Builder) Ping() error {
 
 // ==================== 私有方法 ====================
 
-func (b *Builder) buildSelect() string {
+func (b *Builder) buildSelect() (string, []interface{}) {
 	var sql strings.Builder
+	var args []interface{}
 
 	sql.WriteString("SELECT")
 	if b.distinct {
@@ -588,104 +795,191 @@ func (b *Builder) buildSelect() string {
 		sql.WriteString(" *")
 	} else {
 		sql.WriteString(" ")
-		sql.WriteString(strings.Join(b.columns, ", "))
+		sql.WriteString(strings.Join(b.quoteIdentifiers(b.columns), ", "))
+	}
+	args = append(args, b.selectArgs...)
+
+	// 窗口函数列
+	if len(b.windowExprs) > 0 {
+		windowCols, windowArgs := b.buildWindowExprs()
+		sql.WriteString(", ")
+		sql.WriteString(strings.Join(windowCols, ", "))
+		args = append(args, windowArgs...)
 	}
 
-	sql.WriteString(fmt.Sprintf(" FROM %s", b.table))
+	// FROM: 表或派生表子查询
+	if b.fromSub != nil {
+		subSQL, subArgs := b.embed(b.fromSub)
+		sql.WriteString(fmt.Sprintf(" FROM (%s)", subSQL))
+		args = append(args, subArgs...)
+	} else {
+		sql.WriteString(fmt.Sprintf(" FROM %s", b.quoteIdentifier(b.table)))
+	}
 	if b.tableAlias != "" {
-		sql.WriteString(fmt.Sprintf(" AS %s", b.tableAlias))
+		sql.WriteString(fmt.Sprintf(" AS %s", b.quoteIdentifier(b.tableAlias)))
 	}
+	sql.WriteString(b.buildTableHint())
 
 	// JOINs
 	for _, j := range b.joins {
 		sql.WriteString(" ")
 		sql.WriteString(j)
 	}
+	args = append(args, b.joinArgs...)
 
-	// WHERE
-	if len(b.wheres) > 0 {
+	// WHERE (游标条件与原有条件以 AND 连接，原有条件整体加括号)
+	keysetWhere, keysetArgs := b.buildKeysetWhere()
+	switch {
+	case len(b.wheres) > 0 && keysetWhere != "":
+		sql.WriteString(fmt.Sprintf(" WHERE (%s) AND %s", strings.Join(b.wheres, " "), keysetWhere))
+	case len(b.wheres) > 0:
 		sql.WriteString(" WHERE ")
 		sql.WriteString(strings.Join(b.wheres, " "))
+	case keysetWhere != "":
+		sql.WriteString(" WHERE " + keysetWhere)
 	}
+	args = append(args, b.args...)
+	args = append(args, keysetArgs...)
 
 	// GROUP BY
 	if len(b.groupByCols) > 0 {
-		sql.WriteString(fmt.Sprintf(" GROUP BY %s", strings.Join(b.groupByCols, ", ")))
+		sql.WriteString(fmt.Sprintf(" GROUP BY %s", strings.Join(b.quoteIdentifiers(b.groupByCols), ", ")))
 	}
 
 	// HAVING
 	if len(b.havings) > 0 {
 		sql.WriteString(fmt.Sprintf(" HAVING %s", strings.Join(b.havings, " AND ")))
 	}
+	args = append(args, b.havingArgs...)
 
-	// ORDER BY
-	if len(b.orderByCols) > 0 {
-		sql.WriteString(fmt.Sprintf(" ORDER BY %s", strings.Join(b.orderByCols, ", ")))
-	}
+	// WINDOW
+	sql.WriteString(b.buildWindowClause())
 
-	// LIMIT
-	if b.limitVal > 0 {
-		sql.WriteString(fmt.Sprintf(" LIMIT %d", b.limitVal))
+	// UNION [ALL]
+	for _, u := range b.unions {
+		unionSQL, unionArgs := b.embed(u.query)
+		if u.all {
+			sql.WriteString(" UNION ALL ")
+		} else {
+			sql.WriteString(" UNION ")
+		}
+		sql.WriteString(unionSQL)
+		args = append(args, unionArgs...)
 	}
 
-	// OFFSET
-	if b.offsetVal >= 0 {
-		sql.WriteString(fmt.Sprintf(" OFFSET %d", b.offsetVal))
+	// ORDER BY (存在UNION时作用于整个结果集，游标排序列优先)
+	orderBy := append(b.buildKeysetOrder(), b.orderByCols...)
+	if len(orderBy) > 0 {
+		sql.WriteString(fmt.Sprintf(" ORDER BY %s", strings.Join(orderBy, ", ")))
 	}
 
-	return sql.String()
+	// LIMIT / OFFSET
+	sql.WriteString(b.buildLimit())
+
+	// FOR UPDATE / FOR SHARE
+	sql.WriteString(b.buildLock())
+
+	return sql.String(), args
 }
 
-func (b *Builder) buildInsert() string {
-	var cols []string
-	var placeholders []string
+func (b *Builder) buildInsert() (string, []interface{}) {
+	columns := orderedColumns(b.insertData, b.insertColumns)
+	cols := make([]string, 0, len(columns))
+	placeholders := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns))
 
-	for k, v := range b.insertData {
-		cols = append(cols, k)
+	for _, k := range columns {
+		cols = append(cols, b.quoteIdentifier(k))
 		placeholders = append(placeholders, "?")
-		b.args = append(b.args, v)
+		args = append(args, b.insertData[k])
 	}
 
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		b.table,
+	return fmt.Sprintf("INSERT INTO %s (%s)%s VALUES (%s)%s",
+		b.quoteIdentifier(b.table),
 		strings.Join(cols, ", "),
-		strings.Join(placeholders, ", "))
+		b.buildOutput("INSERTED"),
+		strings.Join(placeholders, ", "),
+		b.buildReturning()), args
 }
 
-func (b *Builder) buildUpdate() string {
+func (b *Builder) buildUpdate() (string, []interface{}) {
 	if len(b.updateData) == 0 {
-		return ""
+		return "", nil
 	}
 
 	var sql strings.Builder
-	sql.WriteString(fmt.Sprintf("UPDATE %s SET ", b.table))
-
-	var setParts []string
-	for k, v := range b.updateData {
-		setParts = append(setParts, fmt.Sprintf("%s = ?", k))
-		b.args = append(b.args, v)
+	sql.WriteString(fmt.Sprintf("UPDATE %s SET ", b.quoteIdentifier(b.table)))
+
+	columns := orderedColumns(b.updateData, b.updateColumns)
+	setParts := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns))
+	for _, k := range columns {
+		column := b.quoteIdentifier(k)
+		if expr, ok := b.updateData[k].(incrementExpr); ok {
+			setParts = append(setParts, fmt.Sprintf("%s = %s %s ?", column, column, expr.op))
+			args = append(args, expr.value)
+			continue
+		}
+		setParts = append(setParts, fmt.Sprintf("%s = ?", column))
+		args = append(args, b.updateData[k])
 	}
 
 	sql.WriteString(strings.Join(setParts, ", "))
+	sql.WriteString(b.buildOutput("INSERTED"))
 
 	// WHERE
 	if len(b.wheres) > 0 {
 		sql.WriteString(" WHERE ")
 		sql.WriteString(strings.Join(b.wheres, " "))
 	}
+	sql.WriteString(b.buildReturning())
 
-	return sql.String()
+	return sql.String(), args
 }
 
 func (b *Builder) buildDelete() string {
 	var sql strings.Builder
-	sql.WriteString(fmt.Sprintf("DELETE FROM %s", b.table))
+	sql.WriteString(fmt.Sprintf("DELETE FROM %s", b.quoteIdentifier(b.table)))
+	sql.WriteString(b.buildOutput("DELETED"))
 
 	// WHERE
 	if len(b.wheres) > 0 {
 		sql.WriteString(" WHERE ")
 		sql.WriteString(strings.Join(b.wheres, " "))
 	}
+	sql.WriteString(b.buildReturning())
 
 	return sql.String()
 }
+
+// sortedKeys 按名称排序的map键，保证列与参数顺序稳定
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// orderedColumns 优先使用调用方指定的列顺序，其余列按名称排序追加
+func orderedColumns(data map[string]interface{}, preferred []string) []string {
+	if len(preferred) == 0 {
+		return sortedKeys(data)
+	}
+
+	columns := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(preferred))
+	for _, col := range preferred {
+		if _, exists := data[col]; exists && !seen[col] {
+			columns = append(columns, col)
+			seen[col] = true
+		}
+	}
+	for _, col := range sortedKeys(data) {
+		if !seen[col] {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,192 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 09:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 09:00:00
+ * @FilePath: \go-sqlbuilder\builder_aggregate.go
+ * @Description: 聚合与取值 - Sum/Avg/Min/Max/Pluck/PluckMap/Value 及泛型版本
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+)
+
+// aggregateAlias 聚合结果列别名
+const aggregateAlias = "aggregate"
+
+// CountDistinct COUNT(DISTINCT column)
+func (b *Builder) CountDistinct(column string) (int64, error) {
+	query := b.Clone()
+	query.distinct = true
+	return query.Count(column)
+}
+
+// Sum SUM(column)，无匹配行时为 nil
+func (b *Builder) Sum(column string) (interface{}, error) {
+	return b.aggregate("SUM", column)
+}
+
+// Avg AVG(column)
+func (b *Builder) Avg(column string) (interface{}, error) {
+	return b.aggregate("AVG", column)
+}
+
+// Min MIN(column)
+func (b *Builder) Min(column string) (interface{}, error) {
+	return b.aggregate("MIN", column)
+}
+
+// Max MAX(column)
+func (b *Builder) Max(column string) (interface{}, error) {
+	return b.aggregate("MAX", column)
+}
+
+// Pluck 取单列值到切片，保留 ORDER BY / LIMIT，dest 为 *[]T
+func (b *Builder) Pluck(column string, dest interface{}) error {
+	return b.pluckQuery(column).Get(dest)
+}
+
+// PluckMap 取两列组成 key => value 映射，key 重复时后者覆盖前者
+func (b *Builder) PluckMap(keyColumn, valueColumn string) (map[interface{}]interface{}, error) {
+	return PluckMap[interface{}, interface{}](b, keyColumn, valueColumn)
+}
+
+// Value 取第一行的单列值，无记录时返回 sql.ErrNoRows
+func (b *Builder) Value(column string) (interface{}, error) {
+	return Value[interface{}](b, column)
+}
+
+// ==================== 泛型版本 ====================
+
+// Pluck 取单列值为 []T
+func Pluck[T any](b *Builder, column string) ([]T, error) {
+	var values []T
+	if err := b.Pluck(column, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// PluckMap 取两列组成 map[K]V
+func PluckMap[K comparable, V any](b *Builder, keyColumn, valueColumn string) (map[K]V, error) {
+	query := b.pluckQuery(keyColumn, valueColumn)
+	cursor, err := query.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	result := make(map[K]V)
+	for cursor.Next() {
+		var key K
+		var value V
+		if err := cursor.rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		result[normalizeScanned(key)] = normalizeScanned(value)
+	}
+	return result, cursor.Err()
+}
+
+// Value 取第一行的单列值为 T
+func Value[T any](b *Builder, column string) (T, error) {
+	var value T
+	query := b.pluckQuery(column)
+	query.limitVal = 1
+	if err := query.First(&value); err != nil {
+		return value, err
+	}
+	return normalizeScanned(value), nil
+}
+
+// ==================== 私有方法 ====================
+
+// aggregate 执行单值聚合
+func (b *Builder) aggregate(function, column string) (interface{}, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.validateColumn(column); err != nil {
+		return nil, err
+	}
+	derived := b.needsDerivedAggregate()
+	query := b.aggregateQuery(fmt.Sprintf("%s(%s)", function, b.aggregateColumn(column, derived)), derived)
+
+	sql, args := query.ToSQL()
+	var value interface{}
+	if err := b.adapter.QueryRowContext(b.ctx, sql, args...).Scan(&value); err != nil {
+		return nil, err
+	}
+	return normalizeScanned(value), nil
+}
+
+// aggregateQuery 生成聚合查询，保留 WHERE / JOIN，忽略 ORDER BY / LIMIT / OFFSET
+// derived 为 true 时将原查询包装为派生表后在外层聚合，分组查询未指定选择列时派生表只选择分组列
+func (b *Builder) aggregateQuery(expr string, derived bool) *Builder {
+	inner := b.Clone()
+	inner.immutable = false
+	inner.orderByCols = nil
+	inner.limitVal = 0
+	inner.offsetVal = 0
+	inner.lockMode = ""
+	inner.keysetCols = nil
+	inner.keysetCursor = ""
+
+	query := inner
+	if derived {
+		// SELECT * ... GROUP BY 在 PostgreSQL / ONLY_FULL_GROUP_BY 下非法
+		if len(inner.columns) == 0 && len(inner.windowExprs) == 0 && len(inner.groupByCols) > 0 {
+			inner.columns = cloneStrings(inner.groupByCols)
+		}
+		query = inner.SubQuery(aggregateAlias + "_sub")
+	} else {
+		query.distinct = false
+		query.selectArgs = nil
+		query.windowExprs = nil
+		query.namedWindows = nil
+	}
+	query.queryType = "select"
+	query.columns = []string{fmt.Sprintf("%s AS %s", expr, aggregateAlias)}
+	return query
+}
+
+// needsDerivedAggregate 存在 GROUP BY / UNION 时聚合需作用于派生表
+func (b *Builder) needsDerivedAggregate() bool {
+	return len(b.groupByCols) > 0 || len(b.unions) > 0
+}
+
+// aggregateColumn 聚合列: 派生表中只能按列名引用
+func (b *Builder) aggregateColumn(column string, derived bool) string {
+	if derived {
+		return b.quoteIdentifier(keyColumnName(column))
+	}
+	return b.quoteIdentifier(column)
+}
+
+// pluckQuery 取值查询: 替换选择列，保留排序与分页
+func (b *Builder) pluckQuery(columns ...string) *Builder {
+	query := b.Clone()
+	query.immutable = false
+	if !query.checkColumns(columns...) {
+		return query
+	}
+	query.queryType = "select"
+	query.columns = cloneStrings(columns)
+	query.selectArgs = nil
+	query.windowExprs = nil
+	query.namedWindows = nil
+	return query
+}
+
+// normalizeScanned 将驱动返回的 []byte 转为 string (仅对 interface{} 目标生效)
+func normalizeScanned[T any](value T) T {
+	if raw, ok := any(value).([]byte); ok {
+		if converted, ok := any(string(raw)).(T); ok {
+			return converted
+		}
+	}
+	return value
+}
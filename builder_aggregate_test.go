@@ -0,0 +1,126 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 09:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 09:00:00
+ * @FilePath: \go-sqlbuilder\builder_aggregate_test.go
+ * @Description: 聚合与取值测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderAggregateSQL 测试聚合查询忽略排序分页、分组时包装派生表
+func TestBuilderAggregateSQL(t *testing.T) {
+	base := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("orders").
+		Where("status", "=", "paid").
+		OrderByDesc("id").
+		Limit(10)
+
+	sql, args := base.aggregateQuery("SUM(\"amount\")", false).ToSQL()
+	assert.Equal(t, `SELECT SUM("amount") AS aggregate FROM "orders" WHERE "status" = $1`, sql)
+	assert.Equal(t, []interface{}{"paid"}, args)
+
+	grouped := base.Clone().Select("user_id").GroupBy("user_id")
+	sql, _ = grouped.aggregateQuery("COUNT(*)", grouped.needsDerivedAggregate()).ToSQL()
+	assert.Equal(t, `SELECT COUNT(*) AS aggregate FROM (SELECT "user_id" FROM "orders" WHERE "status" = $1 GROUP BY "user_id") AS "aggregate_sub"`, sql)
+
+	// 未指定选择列时派生表只选择分组列
+	implicit := (&Builder{ctx: context.Background()}).WithDialect("postgres").Table("orders").GroupBy("user_id")
+	sql, _ = implicit.aggregateQuery("COUNT(*)", implicit.needsDerivedAggregate()).ToSQL()
+	assert.Equal(t, `SELECT COUNT(*) AS aggregate FROM (SELECT "user_id" FROM "orders" GROUP BY "user_id") AS "aggregate_sub"`, sql)
+	assert.Empty(t, implicit.columns)
+
+	t.Logf("✓ 分组计数SQL: %s", sql)
+}
+
+// TestBuilderAggregates 测试在SQLite上执行聚合
+func TestBuilderAggregates(t *testing.T) {
+	db := newSQLiteDB(t)
+	users := func() *Builder { return newSQLiteBuilder(t, db).Table("users") }
+
+	sum, err := users().Sum("age")
+	require.NoError(t, err)
+	assert.Equal(t, int64(55), sum)
+
+	avg, err := users().Where("status", "=", "active").Avg("age")
+	require.NoError(t, err)
+	assert.Equal(t, float64(30), avg)
+
+	minName, err := users().Min("name")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", minName)
+
+	maxAge, err := users().OrderBy("id").Limit(1).Max("age")
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), maxAge)
+
+	count, err := users().Count("email")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	distinct, err := users().CountDistinct("status")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), distinct)
+
+	groups, err := users().Select("status").GroupBy("status").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), groups)
+
+	groups, err = users().GroupBy("status").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), groups)
+
+	distinctRows, err := users().Distinct().Select("status").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), distinctRows)
+
+	t.Logf("✓ 聚合: sum=%v avg=%v min=%v max=%v", sum, avg, minName, maxAge)
+}
+
+// TestBuilderPluckAndValue 测试 Pluck / PluckMap / Value 及泛型版本
+func TestBuilderPluckAndValue(t *testing.T) {
+	db := newSQLiteDB(t)
+	users := func() *Builder { return newSQLiteBuilder(t, db).Table("users") }
+
+	var names []string
+	require.NoError(t, users().OrderByDesc("id").Pluck("name", &names))
+	assert.Equal(t, []string{"Carol", "Bob", "Alice"}, names)
+
+	emails, err := Pluck[sql.NullString](users().OrderBy("id"), "email")
+	require.NoError(t, err)
+	assert.Len(t, emails, 3)
+	assert.False(t, emails[1].Valid)
+
+	byID, err := PluckMap[int64, string](users(), "id", "name")
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "Alice", 2: "Bob", 3: "Carol"}, byID)
+
+	raw, err := users().PluckMap("name", "status")
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", raw["Bob"])
+
+	name, err := Value[string](users().Where("id", "=", 2), "name")
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", name)
+
+	value, err := users().OrderByDesc("age").Value("name")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", value)
+
+	_, err = users().Where("id", "=", 99).Value("name")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	t.Logf("✓ Pluck: %v, PluckMap: %v", names, byID)
+}
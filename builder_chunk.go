@@ -0,0 +1,234 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 16:00:00
+ * @FilePath: \go-sqlbuilder\builder_chunk.go
+ * @Description: 分块与流式遍历 - 按主键 keyset 分页、游标与 iter.Seq2
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"database/sql"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// ==================== 分块 ====================
+
+// Chunk 按主键 id 分块遍历，records 为 []map[string]interface{}
+func (b *Builder) Chunk(size int, callback func(records interface{}) error) error {
+	return b.ChunkByID(size, constant.FieldID, callback)
+}
+
+// ChunkByID 按指定的单调递增列分块遍历 (keyset: WHERE col > 上一块末尾值 ORDER BY col LIMIT size)
+// 与 OFFSET 分页不同，深度翻页不会变慢，遍历期间插入新行也不会导致重复或遗漏
+func (b *Builder) ChunkByID(size int, column string, callback func(records interface{}) error) error {
+	return ChunkInto(b, size, column, func(records []map[string]interface{}) error {
+		return callback(records)
+	})
+}
+
+// ChunkInto 按 column 分块扫描到 []T 并回调，回调返回错误时停止
+// T 支持结构体、结构体指针与 map[string]interface{}，结果中必须包含 column 列
+func ChunkInto[T any](b *Builder, size int, column string, fn func([]T) error) error {
+	if size <= 0 {
+		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgChunkSizeInvalid)
+	}
+
+	base := b.Clone()
+	base.immutable = false
+	// 原有条件整体加括号，避免 OR 与分页条件的优先级问题
+	if len(base.wheres) > 1 {
+		base.wheres = []string{"(" + strings.Join(base.wheres, " ") + ")"}
+	}
+	base.orderByCols = nil
+	base.OrderBy(column)
+	base.limitVal = int64(size)
+	base.offsetVal = 0
+
+	key := keyColumnName(column)
+	var last interface{}
+	for {
+		page := base.Clone()
+		if last != nil {
+			page.Where(column, ">", last)
+		}
+
+		var records []T
+		if err := page.Get(&records); err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+		if len(records) < size {
+			return nil
+		}
+
+		value, ok := keyValueOf(reflect.ValueOf(records[len(records)-1]), key)
+		if !ok {
+			return errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgChunkKeyNotFound, column)
+		}
+		last = value
+	}
+}
+
+// keyColumnName users.id / "id" -> id
+func keyColumnName(column string) string {
+	if idx := strings.LastIndex(column, "."); idx >= 0 {
+		column = column[idx+1:]
+	}
+	return strings.Trim(column, "`\"[]")
+}
+
+// keyValueOf 从 map 或结构体中取出列值
+func keyValueOf(v reflect.Value, key string) (interface{}, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		value := v.MapIndex(reflect.ValueOf(key))
+		if !value.IsValid() || value.IsNil() {
+			return nil, false
+		}
+		return value.Interface(), true
+	case reflect.Struct:
+		index, ok := structFields(v.Type())[key]
+		if !ok {
+			return nil, false
+		}
+		field, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return nil, false
+		}
+		return field.Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// ==================== 游标 ====================
+
+// Cursor 基于 *sql.Rows 的逐行游标，使用完毕必须调用 Close
+type Cursor struct {
+	rows    *sql.Rows
+	columns []string
+}
+
+// Cursor 执行查询并返回游标，结果不会整体加载到内存
+func (b *Builder) Cursor() (*Cursor, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	sql, args := b.ToSQL()
+	rows, err := b.adapter.QueryContext(b.ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &Cursor{rows: rows, columns: columns}, nil
+}
+
+// Next 移动到下一行
+func (c *Cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan 扫描当前行，dest 支持 *struct / **struct / *map[string]interface{} / *标量
+func (c *Cursor) Scan(dest interface{}) error {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
+	}
+	if target.Elem().Kind() == reflect.Ptr && !isScalarType(target.Elem().Type()) {
+		if target.Elem().IsNil() {
+			target.Elem().Set(reflect.New(target.Elem().Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	return scanCurrent(c.rows, c.columns, target)
+}
+
+// Columns 结果列名
+func (c *Cursor) Columns() []string {
+	return c.columns
+}
+
+// Err 遍历过程中的错误
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+// Close 释放连接
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
+
+// Each 逐行回调，每行为独立的 map，回调返回错误时停止
+func (b *Builder) Each(fn func(row map[string]interface{}) error) error {
+	cursor, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		var row map[string]interface{}
+		if err := cursor.Scan(&row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Iter 以 iter.Seq2 逐行遍历查询结果，提前 break 时自动释放连接
+//
+//	for user, err := range sqlbuilder.Iter[User](builder.Table("users")) { ... }
+func Iter[T any](b *Builder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor, err := b.Cursor()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer cursor.Close()
+
+		for cursor.Next() {
+			var item T
+			if err := cursor.Scan(&item); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
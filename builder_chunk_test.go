@@ -0,0 +1,92 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 16:00:00
+ * @FilePath: \go-sqlbuilder\builder_chunk_test.go
+ * @Description: 分块与流式遍历测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderChunk 测试按主键 keyset 分块
+func TestBuilderChunk(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var pages [][]interface{}
+	err := newSQLiteBuilder(t, db).Table("users").Chunk(2, func(records interface{}) error {
+		var ids []interface{}
+		for _, row := range records.([]map[string]interface{}) {
+			ids = append(ids, row["id"])
+		}
+		pages = append(pages, ids)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(1), int64(2)}, {int64(3)}}, pages)
+
+	t.Logf("✓ 分块结果: %v", pages)
+}
+
+// TestChunkIntoWithOrCondition 测试泛型分块与 OR 条件的优先级
+func TestChunkIntoWithOrCondition(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	query := newSQLiteBuilder(t, db).Table("users").
+		Where("name", "=", "Alice").
+		OrWhere("name", "=", "Carol")
+
+	var names []string
+	err := ChunkInto(query, 1, "id", func(users []*scanUser) error {
+		for _, u := range users {
+			names = append(names, u.Name)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Carol"}, names)
+
+	err = ChunkInto(query, 0, "id", func([]scanUser) error { return nil })
+	assert.Error(t, err)
+
+	t.Logf("✓ 泛型分块结果: %v", names)
+}
+
+// TestBuilderCursorAndIter 测试游标、Each 与 iter.Seq2
+func TestBuilderCursorAndIter(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var statuses []interface{}
+	err := newSQLiteBuilder(t, db).Table("users").OrderBy("id").Each(func(row map[string]interface{}) error {
+		statuses = append(statuses, row["status"])
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"active", "inactive", "active"}, statuses)
+
+	var names []string
+	for user, err := range Iter[scanUser](newSQLiteBuilder(t, db).Table("users").OrderBy("id")) {
+		require.NoError(t, err)
+		names = append(names, user.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"Alice", "Bob"}, names)
+
+	// 提前 break 后连接已释放，后续查询可正常执行 (MaxOpenConns = 1)
+	count, err := newSQLiteBuilder(t, db).Table("users").Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	t.Logf("✓ 流式遍历: %v", names)
+}
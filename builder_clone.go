@@ -0,0 +1,144 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 14:00:00
+ * @FilePath: \go-sqlbuilder\builder_clone.go
+ * @Description: 构建器克隆与不可变模式
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+// Clone 深拷贝构建器，副本与原构建器互不影响 (并发安全)
+func (b *Builder) Clone() *Builder {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return &Builder{
+		adapter:        b.adapter,
+		ctx:            b.ctx,
+		timeout:        b.timeout,
+		driver:         b.driver,
+		quoteIdent:     b.quoteIdent,
+		immutable:      b.immutable,
+		safeMode:       b.safeMode,
+		validator:      b.validator,
+		err:            b.err,
+		txDepth:        b.txDepth,
+		table:          b.table,
+		tableAlias:     b.tableAlias,
+		distinct:       b.distinct,
+		columns:        cloneStrings(b.columns),
+		joins:          cloneStrings(b.joins),
+		wheres:         cloneStrings(b.wheres),
+		havings:        cloneStrings(b.havings),
+		groupByCols:    cloneStrings(b.groupByCols),
+		orderByCols:    cloneStrings(b.orderByCols),
+		limitVal:       b.limitVal,
+		offsetVal:      b.offsetVal,
+		insertData:     cloneMap(b.insertData),
+		insertColumns:  cloneStrings(b.insertColumns),
+		updateData:     cloneMap(b.updateData),
+		updateColumns:  cloneStrings(b.updateColumns),
+		deleteWhere:    b.deleteWhere,
+		upsertRows:     cloneRows(b.upsertRows),
+		upsertConflict: cloneStrings(b.upsertConflict),
+		upsertUpdate:   cloneStrings(b.upsertUpdate),
+		upsertSets:     cloneUpsertSets(b.upsertSets),
+		upsertNothing:  b.upsertNothing,
+		returningCols:  cloneStrings(b.returningCols),
+		returningKey:   b.returningKey,
+		lockMode:       b.lockMode,
+		lockWait:       b.lockWait,
+		lockOf:         cloneStrings(b.lockOf),
+		keysetCols:     cloneKeysetColumns(b.keysetCols),
+		keysetCursor:   b.keysetCursor,
+		keysetBefore:   b.keysetBefore,
+		fromSub:        b.fromSub,
+		unions:         cloneUnions(b.unions),
+		ctes:           cloneCTEs(b.ctes),
+		windowExprs:    cloneWindowExprs(b.windowExprs),
+		namedWindows:   cloneNamedWindows(b.namedWindows),
+		selectArgs:     cloneArgs(b.selectArgs),
+		joinArgs:       cloneArgs(b.joinArgs),
+		args:           cloneArgs(b.args),
+		havingArgs:     cloneArgs(b.havingArgs),
+		queryType:      b.queryType,
+	}
+}
+
+// Immutable 返回不可变构建器，其后每次链式调用都返回新的副本
+// 适用于定义公共查询模板并在多个请求/协程中派生查询
+func (b *Builder) Immutable() *Builder {
+	clone := b.Clone()
+	clone.immutable = true
+	return clone
+}
+
+// Mutable 返回可变构建器副本，链式调用恢复为原地修改
+func (b *Builder) Mutable() *Builder {
+	clone := b.Clone()
+	clone.immutable = false
+	return clone
+}
+
+// IsImmutable 是否处于不可变模式
+func (b *Builder) IsImmutable() bool {
+	return b.immutable
+}
+
+// derive 链式调用的修改目标: 不可变模式下返回副本，否则返回自身
+func (b *Builder) derive() *Builder {
+	if b.immutable {
+		return b.Clone()
+	}
+	return b
+}
+
+func cloneStrings(src []string) []string {
+	if src == nil {
+		return nil
+	}
+	dst := make([]string, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func cloneArgs(src []interface{}) []interface{} {
+	if src == nil {
+		return nil
+	}
+	dst := make([]interface{}, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func cloneUnions(src []unionClause) []unionClause {
+	if src == nil {
+		return nil
+	}
+	dst := make([]unionClause, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func cloneCTEs(src []cteClause) []cteClause {
+	if src == nil {
+		return nil
+	}
+	dst := make([]cteClause, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func cloneMap(src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,86 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 11:00:00
+ * @FilePath: \go-sqlbuilder\builder_column_test.go
+ * @Description: 类型安全列描述符测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/kamalyes/go-sqlbuilder/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUsers 模拟 sqlbuilder-gen 生成的列描述符
+var testUsers = struct {
+	ID     column.Column[int64]
+	Name   column.Column[string]
+	Age    column.Column[int]
+	Email  column.Column[string]
+	Status column.Column[string]
+}{
+	ID:     column.New[int64]("id"),
+	Name:   column.New[string]("name"),
+	Age:    column.New[int]("age"),
+	Email:  column.New[string]("email"),
+	Status: column.New[string]("status"),
+}
+
+// TestWhereCond_SQL 测试列条件生成的SQL
+func TestWhereCond_SQL(t *testing.T) {
+	b := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("users").
+		WhereCond(testUsers.Status.Eq("active"), testUsers.Age.Between(18, 30)).
+		OrWhereCond(testUsers.ID.In(1, 2, 3))
+
+	sql, args := b.ToSQL()
+	assert.Equal(t, `SELECT * FROM "users" WHERE "status" = $1 AND "age" BETWEEN $2 AND $3 OR "id" IN ($4,$5,$6)`, sql)
+	assert.Equal(t, []interface{}{"active", 18, 30, int64(1), int64(2), int64(3)}, args)
+
+	sql, args = (&Builder{ctx: context.Background()}).WithDialect("mysql").
+		Table("users").WhereCond(testUsers.Email.IsNull(), testUsers.Name.Contains("li")).ToSQL()
+	assert.Equal(t, "SELECT * FROM `users` WHERE `email` IS NULL AND `name` LIKE ?", sql)
+	assert.Equal(t, []interface{}{"%li%"}, args)
+	t.Logf("✓ 列条件SQL测试通过")
+}
+
+// TestWhereCond_Query 测试列条件执行查询
+func TestWhereCond_Query(t *testing.T) {
+	db := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, db)
+
+	var names []string
+	err := b.Table("users").
+		WhereCond(testUsers.Status.Eq("active"), testUsers.Email.IsNotNull()).
+		OrderBy("id").
+		Pluck("name", &names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Carol"}, names)
+	t.Logf("✓ 列条件查询测试通过")
+}
+
+// TestColumnCondition_ParamAndFilter 测试列条件用于 query.Param 与 repository.Filter
+func TestColumnCondition_ParamAndFilter(t *testing.T) {
+	param := query.NewParam().AddCondition(testUsers.Status.Eq("active"), testUsers.Age.Gte(18))
+	sql, args := param.BuildWhereClause()
+	assert.Contains(t, sql, "status = ?")
+	assert.Contains(t, sql, "age >= ?")
+	assert.Equal(t, []interface{}{"active", 18}, args)
+
+	filter := repository.NewConditionFilter(testUsers.ID.NotIn(1, 2))
+	assert.Equal(t, "id", filter.Field)
+	assert.Equal(t, string(constant.OP_NOT_IN), filter.Operator)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, filter.Value)
+	t.Logf("✓ 列条件适配测试通过")
+}
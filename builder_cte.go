@@ -0,0 +1,76 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 16:00:00
+ * @FilePath: \go-sqlbuilder\builder_cte.go
+ * @Description: 公用表表达式 (WITH / WITH RECURSIVE)
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+)
+
+// cteClause WITH 子句中的一个公用表表达式
+type cteClause struct {
+	name      string
+	query     *Builder // 普通CTE查询或递归CTE的锚点查询
+	recursive *Builder // 递归部分，非递归CTE为nil
+}
+
+// With 添加公用表表达式，主查询可通过 Table(name) 引用
+func (b *Builder) With(name string, query *Builder) *Builder {
+	b = b.derive()
+	if !b.checkTables(name) {
+		return b
+	}
+	b.ctes = append(b.ctes, cteClause{name: name, query: query.Clone()})
+	return b
+}
+
+// WithRecursive 添加递归公用表表达式: name AS (anchor UNION ALL recursive)
+// recursive 查询中通过 Table(name) 引用自身
+func (b *Builder) WithRecursive(name string, anchor, recursive *Builder) *Builder {
+	b = b.derive()
+	if !b.checkTables(name) {
+		return b
+	}
+	b.ctes = append(b.ctes, cteClause{name: name, query: anchor.Clone(), recursive: recursive.Clone()})
+	return b
+}
+
+// buildWith 生成 WITH 子句 (含结尾空格) 及其参数
+func (b *Builder) buildWith() (string, []interface{}) {
+	if len(b.ctes) == 0 {
+		return "", nil
+	}
+
+	var args []interface{}
+	var recursive bool
+	parts := make([]string, 0, len(b.ctes))
+
+	for _, cte := range b.ctes {
+		sql, cteArgs := b.embed(cte.query)
+		args = append(args, cteArgs...)
+		if cte.recursive != nil {
+			recursive = true
+			recursiveSQL, recursiveArgs := b.embed(cte.recursive)
+			sql = fmt.Sprintf("%s UNION ALL %s", sql, recursiveSQL)
+			args = append(args, recursiveArgs...)
+		}
+		parts = append(parts, fmt.Sprintf("%s AS (%s)", b.quoteIdentifier(cte.name), sql))
+	}
+
+	keyword := "WITH "
+	// SQL Server 不使用 RECURSIVE 关键字
+	if recursive && b.GetDialect() != constant.DialectSQLServer {
+		keyword = "WITH RECURSIVE "
+	}
+	return keyword + strings.Join(parts, ", ") + " ", args
+}
@@ -0,0 +1,69 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 16:00:00
+ * @FilePath: \go-sqlbuilder\builder_cte_test.go
+ * @Description: 公用表表达式测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderWith 测试WITH子句及参数顺序
+func TestBuilderWith(t *testing.T) {
+	paid := (&Builder{ctx: context.Background()}).
+		Table("orders").
+		Select("user_id").
+		Where("status", "=", "paid")
+
+	sql, args := (&Builder{ctx: context.Background()}).
+		WithDialect("postgres").
+		With("paid_users", paid).
+		Table("users").
+		Join("paid_users", "paid_users.user_id = users.id").
+		Where("users.age", ">", 18).
+		ToSQL()
+
+	assert.Equal(t, `WITH "paid_users" AS (SELECT "user_id" FROM "orders" WHERE status = $1) SELECT * FROM "users" INNER JOIN paid_users ON paid_users.user_id = users.id WHERE "users"."age" > $2`, sql)
+	assert.Equal(t, []interface{}{"paid", 18}, args)
+
+	t.Logf("✓ WITH SQL: %s", sql)
+}
+
+// TestBuilderWithRecursive 测试递归CTE查询组织树
+func TestBuilderWithRecursive(t *testing.T) {
+	db := newSQLiteDB(t)
+	_, err := db.Exec(`CREATE TABLE departments (id INTEGER PRIMARY KEY, parent_id INTEGER, name TEXT);
+		INSERT INTO departments VALUES (1, NULL, 'root'), (2, 1, 'sales'), (3, 2, 'east'), (4, NULL, 'other')`)
+	require.NoError(t, err)
+
+	anchor := newSQLiteBuilder(t, db).Table("departments").Select("id", "name").Where("id", "=", 1)
+	recursive := newSQLiteBuilder(t, db).
+		Table("departments").
+		As("d").
+		Select("d.id", "d.name").
+		Join("tree", "tree.id = d.parent_id")
+
+	query := newSQLiteBuilder(t, db).
+		WithRecursive("tree", anchor, recursive).
+		Table("tree").
+		Select("name").
+		OrderBy("id")
+
+	sql, _ := query.ToSQL()
+	assert.Contains(t, sql, `WITH RECURSIVE "tree" AS (SELECT "id", "name" FROM "departments" WHERE "id" = ? UNION ALL SELECT`)
+
+	var names []string
+	require.NoError(t, query.Get(&names))
+	assert.Equal(t, []string{"root", "sales", "east"}, names)
+}
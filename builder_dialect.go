@@ -0,0 +1,173 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_dialect.go
+ * @Description: 方言感知的占位符与标识符渲染
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+)
+
+// simpleIdentifierPattern 可安全加引号的标识符: name / table.name / table.*
+var simpleIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*|\.\*)*$`)
+
+// normalizeDialect 将驱动名/方言别名归一化为 constant.Dialect*
+func normalizeDialect(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "mysql", "mariadb":
+		return constant.DialectMySQL
+	case "postgres", "postgresql", "pgx", "pq":
+		return constant.DialectPostgres
+	case "sqlite", "sqlite3":
+		return constant.DialectSQLite
+	case "sqlserver", "mssql":
+		return constant.DialectSQLServer
+	default:
+		return ""
+	}
+}
+
+// resolveDriverAdapter 根据方言名称获取驱动适配器，未知方言返回nil
+func resolveDriverAdapter(name string) DriverAdapterInterface {
+	dialect := normalizeDialect(name)
+	if dialect == "" {
+		return nil
+	}
+	driver, err := CreateAdapter(dialect)
+	if err != nil {
+		return nil
+	}
+	return driver
+}
+
+// WithDialect 显式指定SQL方言 (mysql/postgres/sqlite/sqlserver)
+// 需在添加条件之前调用，条件中的标识符在添加时即完成引用
+func (b *Builder) WithDialect(dialect string) *Builder {
+	b = b.derive()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.driver = resolveDriverAdapter(dialect)
+	b.quoteIdent = b.driver != nil
+	return b
+}
+
+// WithQuoteIdentifiers 开启/关闭标识符引用
+// 已知方言默认开启，依赖大小写折叠的旧表结构可关闭
+func (b *Builder) WithQuoteIdentifiers(enable bool) *Builder {
+	b = b.derive()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.quoteIdent = enable
+	return b
+}
+
+// GetDialect 获取当前方言，未知时返回空字符串
+func (b *Builder) GetDialect() string {
+	if b.driver == nil {
+		return ""
+	}
+	return b.driver.DriverName()
+}
+
+// quoteIdentifier 按方言引用标识符，安全模式下始终引用
+// 仅处理简单标识符，表达式/函数/别名等保持原样
+func (b *Builder) quoteIdentifier(ident string) string {
+	if !(b.quoteIdent || b.safeMode) || b.driver == nil || !simpleIdentifierPattern.MatchString(ident) {
+		return ident
+	}
+	parts := strings.Split(ident, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = b.driver.QuoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteIdentifiers 批量引用标识符
+func (b *Builder) quoteIdentifiers(idents []string) []string {
+	quoted := make([]string, len(idents))
+	for i, ident := range idents {
+		quoted[i] = b.quoteIdentifier(ident)
+	}
+	return quoted
+}
+
+// placeholder 第 index 个参数的方言占位符，驱动适配器未实现 Placeholder 时使用 ?
+func (b *Builder) placeholder(index int) string {
+	if adapter, ok := b.driver.(placeholderAdapter); ok {
+		return adapter.Placeholder(index)
+	}
+	return constant.ParameterPlaceholder
+}
+
+// rebindPlaceholders 将 ? 占位符改写为方言占位符 ($n / @pN)
+// 跳过字符串字面量与引用标识符中的 ?；GORM 自行处理占位符，不做改写
+func (b *Builder) rebindPlaceholders(query string) string {
+	if b.placeholder(1) == constant.ParameterPlaceholder {
+		return query
+	}
+	if b.adapter != nil && strings.EqualFold(b.adapter.GetAdapterType(), constant.AdapterTypeGORM) {
+		return query
+	}
+
+	var sql strings.Builder
+	sql.Grow(len(query) + 8)
+
+	bracketQuote := b.driver.DriverName() == constant.DialectSQLServer
+	var quote byte
+	index := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			sql.WriteByte(c)
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			sql.WriteByte(c)
+		case c == '[' && bracketQuote:
+			quote = ']'
+			sql.WriteByte(c)
+		case c == '?':
+			index++
+			sql.WriteString(b.placeholder(index))
+		default:
+			sql.WriteByte(c)
+		}
+	}
+	return sql.String()
+}
+
+// buildLimit 按方言生成 LIMIT/OFFSET 子句
+func (b *Builder) buildLimit() string {
+	if b.driver == nil {
+		var sql strings.Builder
+		if b.limitVal > 0 {
+			sql.WriteString(fmt.Sprintf(" LIMIT %d", b.limitVal))
+		}
+		if b.offsetVal > 0 {
+			sql.WriteString(fmt.Sprintf(" OFFSET %d", b.offsetVal))
+		}
+		return sql.String()
+	}
+
+	clause := b.driver.BuildLimit(b.offsetVal, b.limitVal)
+	// SQL Server 的 OFFSET/FETCH 必须跟随 ORDER BY，游标排序列同样渲染为 ORDER BY
+	if clause != "" && b.driver.DriverName() == constant.DialectSQLServer && len(b.orderByCols) == 0 && len(b.keysetCols) == 0 {
+		clause = " ORDER BY (SELECT NULL)" + clause
+	}
+	return clause
+}
@@ -0,0 +1,148 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_dialect_test.go
+ * @Description: 方言渲染测试 - 不依赖真实数据库
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuilderDialectPostgres 测试PostgreSQL编号占位符
+func TestBuilderDialectPostgres(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, args := builder.
+		WithDialect("pgx").
+		Table("users").
+		Select("id", "name").
+		Where("status", "=", "active").
+		WhereIn("role", "admin", "editor").
+		Limit(10).
+		Offset(20).
+		ToSQL()
+
+	assert.Equal(t, `SELECT "id", "name" FROM "users" WHERE "status" = $1 AND "role" IN ($2,$3) LIMIT 10 OFFSET 20`, sql)
+	assert.Len(t, args, 3)
+
+	t.Logf("✓ Postgres SQL: %s", sql)
+}
+
+// TestBuilderDialectSQLServer 测试SQL Server命名占位符与OFFSET/FETCH
+func TestBuilderDialectSQLServer(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, _ := builder.
+		WithDialect("mssql").
+		Table("users").
+		Where("age", ">", 18).
+		Where("name", "LIKE", "a%").
+		Paginate(2, 10).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM [users] WHERE [age] > @p1 AND [name] LIKE @p2 ORDER BY (SELECT NULL) OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+
+	// 游标排序已提供 ORDER BY，不再追加 (SELECT NULL)
+	keyset, _ := (&Builder{ctx: context.Background()}).
+		WithDialect("mssql").
+		Table("users").
+		OrderByKeyDesc("created_at").
+		OrderByKey("id").
+		Limit(10).
+		ToSQL()
+	assert.Equal(t, "SELECT * FROM [users] ORDER BY [created_at] DESC, [id] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", keyset)
+
+	t.Logf("✓ SQL Server SQL: %s", sql)
+}
+
+// TestBuilderDialectMySQL 测试MySQL反引号引用
+func TestBuilderDialectMySQL(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, _ := builder.
+		WithDialect("mysql").
+		Table("orders").
+		As("o").
+		Select("o.id", "o.*", "COUNT(*) as total").
+		Where("o.order", "=", 1).
+		GroupBy("o.id").
+		OrderByDesc("o.id").
+		Limit(5).
+		Offset(10).
+		ToSQL()
+
+	assert.Equal(t, "SELECT `o`.`id`, `o`.*, COUNT(*) as total FROM `orders` AS `o` WHERE `o`.`order` = ? GROUP BY `o`.`id` ORDER BY `o`.`id` DESC LIMIT 10, 5", sql)
+
+	t.Logf("✓ MySQL SQL: %s", sql)
+}
+
+// TestBuilderDialectSkipsLiterals 测试字符串字面量中的问号不被改写
+func TestBuilderDialectSkipsLiterals(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, args := builder.
+		WithDialect("postgres").
+		Table("faq").
+		WhereRaw("question <> '?' AND title = ?", "hello").
+		Where("id", ">", 1).
+		ToSQL()
+
+	assert.Equal(t, `SELECT * FROM "faq" WHERE question <> '?' AND title = $1 AND "id" > $2`, sql)
+	assert.Len(t, args, 2)
+}
+
+// TestBuilderDialectQuoteDisabled 测试关闭标识符引用
+func TestBuilderDialectQuoteDisabled(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, _ := builder.
+		WithDialect("sqlite3").
+		WithQuoteIdentifiers(false).
+		Table("users").
+		Where("id", "=", 1).
+		Offset(5).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? LIMIT -1 OFFSET 5", sql)
+	assert.Equal(t, "sqlite", builder.GetDialect())
+}
+
+// TestBuilderDialectDefault 测试未知方言保持原有输出
+func TestBuilderDialectDefault(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	sql, _ := builder.
+		Table("users").
+		Where("id", "=", 1).
+		Limit(10).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? LIMIT 10", sql)
+	assert.Empty(t, builder.GetDialect())
+}
+
+// legacyDriver 未实现 Placeholder 的自定义驱动适配器
+type legacyDriver struct {
+	DriverAdapterInterface
+}
+
+// TestBuilderDialectLegacyDriver 测试未实现 Placeholder 的驱动适配器回退为 ? 占位符
+func TestBuilderDialectLegacyDriver(t *testing.T) {
+	var driver DriverAdapterInterface = legacyDriver{NewPostgreSQLDriverAdapter()}
+	builder := &Builder{ctx: context.Background(), driver: driver, quoteIdent: true}
+
+	sql, _ := builder.Table("users").Where("id", "=", 1).ToSQL()
+	assert.Equal(t, `SELECT * FROM "users" WHERE "id" = ?`, sql)
+
+	t.Logf("✓ Legacy driver SQL: %s", sql)
+}
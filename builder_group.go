@@ -0,0 +1,150 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_group.go
+ * @Description: 分组(嵌套括号) WHERE 条件与 query.FilterGroup 编译
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+)
+
+// WhereGroup AND (分组条件)，如 a = ? AND (b = ? OR c = ?)
+func (b *Builder) WhereGroup(fn func(*Builder)) *Builder {
+	return b.addWhereGroup("AND", fn)
+}
+
+// OrWhereGroup OR (分组条件)
+func (b *Builder) OrWhereGroup(fn func(*Builder)) *Builder {
+	return b.addWhereGroup("OR", fn)
+}
+
+// WhereFilterGroup 将 query.FilterGroup (含嵌套 Groups) 编译为分组条件
+func (b *Builder) WhereFilterGroup(group *query.FilterGroup) *Builder {
+	return b.addFilterGroup("AND", group)
+}
+
+// OrWhereFilterGroup 以 OR 连接 query.FilterGroup
+func (b *Builder) OrWhereFilterGroup(group *query.FilterGroup) *Builder {
+	return b.addFilterGroup("OR", group)
+}
+
+// WhereCond 以 AND 追加类型安全的列条件，如 WhereCond(Users.Email.Eq(v), Users.Age.Gte(18))
+func (b *Builder) WhereCond(conds ...column.Condition) *Builder {
+	b = b.derive()
+	for _, cond := range conds {
+		b.addFilterCondition("AND", cond.Field, string(cond.Operator), cond.Value)
+	}
+	return b
+}
+
+// OrWhereCond 以 OR 追加类型安全的列条件
+func (b *Builder) OrWhereCond(conds ...column.Condition) *Builder {
+	b = b.derive()
+	for _, cond := range conds {
+		b.addFilterCondition("OR", cond.Field, string(cond.Operator), cond.Value)
+	}
+	return b
+}
+
+func (b *Builder) addFilterGroup(boolean string, group *query.FilterGroup) *Builder {
+	if group.IsEmpty() {
+		return b
+	}
+	return b.addWhereGroup(boolean, func(g *Builder) {
+		connector := group.Connector()
+		for _, filter := range group.Filters {
+			g.addFilterCondition(connector, filter.Field, string(filter.Operator), filter.Value)
+		}
+		for _, sub := range group.Groups {
+			g.addFilterGroup(connector, sub)
+		}
+	})
+}
+
+// addWhereGroup 在独立的可变构建器上收集条件，整体加括号后并入当前条件
+func (b *Builder) addWhereGroup(boolean string, fn func(*Builder)) *Builder {
+	group := &Builder{
+		adapter:    b.adapter,
+		ctx:        b.ctx,
+		driver:     b.driver,
+		quoteIdent: b.quoteIdent,
+		safeMode:   b.safeMode,
+		validator:  b.validator,
+	}
+	fn(group)
+
+	if group.err != nil {
+		b = b.derive()
+		b.reject(group.err)
+		return b
+	}
+	if len(group.wheres) == 0 {
+		return b
+	}
+
+	b = b.derive()
+	return b.appendWhere(boolean, "("+strings.Join(group.wheres, " ")+")", group.args...)
+}
+
+// addFilterCondition 按操作符追加单个过滤条件
+func (b *Builder) addFilterCondition(boolean, field, operator string, value interface{}) *Builder {
+	operator, ok := b.checkOperator(operator)
+	if !ok || !b.checkColumns(field) {
+		return b
+	}
+	sql, args, err := b.filterConditionSQL(field, operator, value)
+	if err != nil {
+		b.reject(err)
+		return b
+	}
+	return b.appendWhere(boolean, sql, args...)
+}
+
+// filterConditionSQL 生成单个过滤条件片段，支持 IN / NOT IN / BETWEEN / IS [NOT] NULL / FIND_IN_SET
+// BETWEEN 的值不是两个时返回错误
+func (b *Builder) filterConditionSQL(field, operator string, value interface{}) (string, []interface{}, error) {
+	quoted := b.quoteIdentifier(field)
+	op := strings.ToUpper(strings.TrimSpace(operator))
+
+	switch constant.Operator(op) {
+	case constant.OP_IN, constant.OP_NOT_IN:
+		values := column.Values(value)
+		if len(values) == 0 {
+			// 空集合: IN 恒假，NOT IN 恒真
+			if constant.Operator(op) == constant.OP_IN {
+				return "1 = 0", nil, nil
+			}
+			return "1 = 1", nil, nil
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")
+		return fmt.Sprintf("%s %s (%s)", quoted, op, placeholders), values, nil
+
+	case constant.OP_BETWEEN:
+		values := column.Values(value)
+		if len(values) != 2 {
+			return "", nil, errors.NewErrorf(errors.ErrorCodeInvalidFilterValue, errors.MsgBetweenValuesInvalid, field, len(values))
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", quoted), values, nil
+
+	case constant.OP_IS_NULL, constant.OP_IS_NOT_NULL:
+		return fmt.Sprintf("%s %s", quoted, op), nil, nil
+
+	case constant.OP_FIND_IN_SET:
+		return fmt.Sprintf("FIND_IN_SET(?, %s) > 0", quoted), []interface{}{value}, nil
+
+	default:
+		return fmt.Sprintf("%s %s ?", quoted, op), []interface{}{value}, nil
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_group_test.go
+ * @Description: 分组WHERE条件测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderWhereGroup 测试分组条件与参数顺序
+func TestBuilderWhereGroup(t *testing.T) {
+	sql, args := (&Builder{ctx: context.Background()}).
+		Table("users").
+		Where("tenant_id", "=", 1).
+		WhereGroup(func(q *Builder) {
+			q.Where("role", "=", "admin").
+				OrWhereGroup(func(q *Builder) {
+					q.Where("role", "=", "editor").WhereNotNull("verified_at")
+				})
+		}).
+		OrWhereGroup(func(q *Builder) {
+			q.WhereIn("id", 7, 8)
+		}).
+		Where("age", ">", 18).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM users WHERE tenant_id = ? AND (role = ? OR (role = ? AND verified_at IS NOT NULL)) OR (id IN (?,?)) AND age > ?", sql)
+	assert.Equal(t, []interface{}{1, "admin", "editor", 7, 8, 18}, args)
+
+	t.Logf("✓ Grouped WHERE SQL: %s", sql)
+}
+
+// TestBuilderWhereGroupEmpty 测试空分组被忽略
+func TestBuilderWhereGroupEmpty(t *testing.T) {
+	sql, args := (&Builder{ctx: context.Background()}).
+		Table("users").
+		WhereGroup(func(q *Builder) {}).
+		Where("id", "=", 1).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", sql)
+	assert.Len(t, args, 1)
+}
+
+// TestBuilderWhereFilterGroup 测试 query.FilterGroup 嵌套编译
+func TestBuilderWhereFilterGroup(t *testing.T) {
+	group := query.NewFilterGroup("AND").
+		AddFilter("status", query.OP_EQ, "active").
+		AddGroup(query.NewFilterGroup("OR").
+			AddFilter("age", query.OP_BETWEEN, [2]interface{}{18, 30}).
+			AddFilter("level", query.OP_IN, []int{1, 2}))
+
+	sql, args := (&Builder{ctx: context.Background()}).
+		WithDialect("postgres").
+		Table("users").
+		Where("tenant_id", "=", 9).
+		WhereFilterGroup(group).
+		ToSQL()
+
+	assert.Equal(t, `SELECT * FROM "users" WHERE "tenant_id" = $1 AND ("status" = $2 AND ("age" BETWEEN $3 AND $4 OR "level" IN ($5,$6)))`, sql)
+	assert.Equal(t, []interface{}{9, "active", 18, 30, 1, 2}, args)
+
+	where, paramArgs := query.NewParam().AddEQ("tenant_id", 9).AddFilterGroup(group).BuildWhereClause()
+	assert.Equal(t, "WHERE tenant_id = ? AND (status = ? AND (age BETWEEN ? AND ? OR level IN (?,?)))", where)
+	assert.Len(t, paramArgs, 6)
+}
+
+// TestFilterConditionValues 测试 BETWEEN 值个数校验、空 IN 与时间范围 / FIND_IN_SET 的稳定顺序
+func TestFilterConditionValues(t *testing.T) {
+	b := (&Builder{ctx: context.Background()}).WithDialect("mysql").Table("users").
+		WhereCond(column.Condition{Field: "age", Operator: constant.OP_BETWEEN, Value: []int{18}})
+	assert.True(t, errors.IsErrorCode(b.Err(), errors.ErrorCodeInvalidFilterValue))
+
+	sql, _ := (&Builder{ctx: context.Background()}).WithDialect("mysql").Table("users").
+		WhereCond(column.Condition{Field: "id", Operator: constant.OP_IN, Value: []int{}}).ToSQL()
+	assert.Equal(t, "SELECT * FROM `users` WHERE 1 = 0", sql)
+
+	where, args := query.NewParam().AddFilter("id", query.OP_IN, []int{}).BuildWhereClause()
+	assert.Equal(t, "WHERE 1 = 0", where)
+	assert.Empty(t, args)
+
+	invalid := query.NewParam().AddFilter("age", query.OP_BETWEEN, []int{18, 30, 40})
+	_, _, err := invalid.BuildWhereClauseSafe(nil, nil)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFilterValue))
+	where, _ = invalid.BuildWhereClause()
+	assert.Equal(t, "WHERE 1 = 0", where)
+
+	param := query.NewParam().
+		AddTimeRange("updated_at", 3, 4).
+		AddTimeRange("created_at", 1, 2).
+		AddFindInSet("tags", "b").
+		AddFindInSet("roles", "a")
+	for i := 0; i < 10; i++ {
+		where, args = param.BuildWhereClause()
+		require.Equal(t, "WHERE created_at BETWEEN ? AND ? AND updated_at BETWEEN ? AND ? AND FIND_IN_SET(?, roles) > 0 AND FIND_IN_SET(?, tags) > 0", where)
+		require.Equal(t, []interface{}{1, 2, 3, 4, "a", "b"}, args)
+	}
+	t.Logf("✓ 过滤值校验测试通过")
+}
+
+// TestFilterOperatorNormalize 测试非安全模式同样规范化兼容操作符，未知操作符原样使用
+func TestFilterOperatorNormalize(t *testing.T) {
+	sql, args := (&Builder{ctx: context.Background()}).WithDialect("mysql").Table("users").
+		Where("status", "eq", "active").
+		WhereCond(column.Condition{Field: "age", Operator: "gte", Value: 18}).
+		OrWhere("name", "lk", "%a%").
+		ToSQL()
+	assert.Equal(t, "SELECT * FROM `users` WHERE `status` = ? AND `age` >= ? OR `name` LIKE ?", sql)
+	assert.Equal(t, []interface{}{"active", 18, "%a%"}, args)
+
+	raw := (&Builder{ctx: context.Background()}).WithDialect("postgres").Table("docs").Where("tags", "@>", "{a}")
+	assert.NoError(t, raw.Err())
+	sql, _ = raw.ToSQL()
+	assert.Equal(t, `SELECT * FROM "docs" WHERE "tags" @> $1`, sql)
+
+	safe := (&Builder{ctx: context.Background()}).WithSafeMode(true).Table("docs").Where("tags", "@>", "{a}")
+	assert.True(t, errors.IsErrorCode(safe.Err(), errors.ErrorCodeInvalidOperator))
+	t.Logf("✓ 操作符规范化测试通过")
+}
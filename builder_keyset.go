@@ -0,0 +1,359 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 17:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 17:00:00
+ * @FilePath: \go-sqlbuilder\builder_keyset.go
+ * @Description: 游标 (keyset) 分页 - 签名游标、After/Before 与多列排序
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/meta"
+)
+
+var (
+	cursorSecretMu sync.RWMutex
+	// cursorSecret 游标签名密钥，默认为进程内随机值
+	cursorSecret = randomCursorSecret()
+)
+
+// SetCursorSecret 设置游标签名密钥
+// 多实例部署时必须设置相同的密钥，否则其它实例签发的游标无法校验
+func SetCursorSecret(secret []byte) {
+	cursorSecretMu.Lock()
+	defer cursorSecretMu.Unlock()
+	cursorSecret = append([]byte(nil), secret...)
+}
+
+func randomCursorSecret() []byte {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// keysetColumn 游标排序列
+type keysetColumn struct {
+	column string
+	desc   bool
+}
+
+// cursorPayload 游标内容: 排序列及上一页边界行的值
+type cursorPayload struct {
+	Columns []string      `json:"c"`
+	Values  []interface{} `json:"v"`
+}
+
+// cursorTimeKey time.Time 在游标中的类型标记，保证往返后仍为时间类型
+const cursorTimeKey = "$t"
+
+// ==================== Builder 集成 ====================
+
+// OrderByKey 添加游标排序列 (升序)，多列时依次作为并列时的次级排序
+// 最后一列应唯一 (通常为主键)，保证翻页既不重复也不遗漏
+func (b *Builder) OrderByKey(column string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.keysetCols = append(b.keysetCols, keysetColumn{column: column})
+	return b
+}
+
+// OrderByKeyDesc 添加游标排序列 (降序)
+func (b *Builder) OrderByKeyDesc(column string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.keysetCols = append(b.keysetCols, keysetColumn{column: column, desc: true})
+	return b
+}
+
+// After 取游标之后的一页 (next_cursor)
+// 游标签名无效或与已指定的排序列不一致时记录错误，由 Err() 和执行方法返回
+func (b *Builder) After(cursor string) *Builder {
+	b = b.derive()
+	b.setKeysetCursor(cursor, false)
+	return b
+}
+
+// Before 取游标之前的一页 (prev_cursor)
+// 游标校验同 After
+func (b *Builder) Before(cursor string) *Builder {
+	b = b.derive()
+	b.setKeysetCursor(cursor, true)
+	return b
+}
+
+// setKeysetCursor 设置游标并立即校验，尚未指定排序列时只校验签名
+func (b *Builder) setKeysetCursor(cursor string, before bool) {
+	b.keysetCursor = cursor
+	b.keysetBefore = before
+	if cursor == "" {
+		return
+	}
+	if len(b.keysetCols) == 0 {
+		if _, err := decodeCursor(cursor); err != nil {
+			b.reject(err)
+		}
+		return
+	}
+	if _, err := b.keysetValues(); err != nil {
+		b.reject(err)
+	}
+}
+
+// CursorPaginate 游标分页查询，dest 为 *[]T，返回下一页/上一页游标
+// 需先通过 OrderByKey / OrderByKeyDesc 指定排序列，且结果中须包含这些列
+func (b *Builder) CursorPaginate(dest interface{}, limit int) (*meta.CursorPage, error) {
+	if len(b.keysetCols) == 0 {
+		return nil, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgKeysetColumnsRequired)
+	}
+	if limit <= 0 {
+		return nil, errors.NewError(errors.ErrorCodePageSizeInvalid, errors.MsgCursorLimitInvalid)
+	}
+	if _, err := b.keysetValues(); err != nil {
+		return nil, err
+	}
+
+	query := b.Clone()
+	query.limitVal = int64(limit + 1)
+	query.offsetVal = 0
+	if err := query.Get(dest); err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	hasMore := rows.Len() > limit
+	if hasMore {
+		rows.Set(rows.Slice(0, limit))
+	}
+	// Before 按反向排序查询，结果需恢复为正常顺序
+	if b.keysetBefore {
+		reverseSlice(rows)
+	}
+
+	page := &meta.CursorPage{Limit: int32(limit)}
+	hasCursor := b.keysetCursor != ""
+	if b.keysetBefore {
+		page.HasNext = hasCursor
+		page.HasPrev = hasMore
+	} else {
+		page.HasNext = hasMore
+		page.HasPrev = hasCursor
+	}
+
+	if rows.Len() == 0 {
+		return page, nil
+	}
+	if page.HasNext {
+		cursor, err := b.encodeRowCursor(rows.Index(rows.Len() - 1))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = cursor
+	}
+	if page.HasPrev {
+		cursor, err := b.encodeRowCursor(rows.Index(0))
+		if err != nil {
+			return nil, err
+		}
+		page.PrevCursor = cursor
+	}
+	return page, nil
+}
+
+// EncodeCursor 按当前游标排序列编码游标，values 与 OrderByKey 的列一一对应
+func (b *Builder) EncodeCursor(values ...interface{}) (string, error) {
+	return encodeCursor(b.keysetColumnNames(), values)
+}
+
+// ==================== SQL 生成 ====================
+
+// buildKeysetWhere 生成游标条件，如 (a > ?) OR (a = ? AND b > ?)
+// 无效游标生成 1 = 0，不会退化为返回第一页；错误由 After / Before 记录
+func (b *Builder) buildKeysetWhere() (string, []interface{}) {
+	values, err := b.keysetValues()
+	if err != nil {
+		return "1 = 0", nil
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	var parts []string
+	var args []interface{}
+	for i, key := range b.keysetCols {
+		conds := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, fmt.Sprintf("%s = ?", b.quoteIdentifier(b.keysetCols[j].column)))
+			args = append(args, values[j])
+		}
+		op := ">"
+		if key.desc != b.keysetBefore {
+			op = "<"
+		}
+		conds = append(conds, fmt.Sprintf("%s %s ?", b.quoteIdentifier(key.column), op))
+		args = append(args, values[i])
+		parts = append(parts, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// buildKeysetOrder 游标排序，Before 时反向
+func (b *Builder) buildKeysetOrder() []string {
+	orders := make([]string, len(b.keysetCols))
+	for i, key := range b.keysetCols {
+		direction := "ASC"
+		if key.desc != b.keysetBefore {
+			direction = "DESC"
+		}
+		orders[i] = fmt.Sprintf("%s %s", b.quoteIdentifier(key.column), direction)
+	}
+	return orders
+}
+
+// keysetValues 解码并校验当前游标
+func (b *Builder) keysetValues() ([]interface{}, error) {
+	if b.keysetCursor == "" {
+		return nil, nil
+	}
+	payload, err := decodeCursor(b.keysetCursor)
+	if err != nil {
+		return nil, err
+	}
+	columns := b.keysetColumnNames()
+	if len(payload.Values) != len(columns) || strings.Join(payload.Columns, ",") != strings.Join(columns, ",") {
+		return nil, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgInvalidCursor)
+	}
+	return payload.Values, nil
+}
+
+func (b *Builder) keysetColumnNames() []string {
+	names := make([]string, len(b.keysetCols))
+	for i, key := range b.keysetCols {
+		names[i] = key.column
+	}
+	return names
+}
+
+// encodeRowCursor 取出行中的排序列值并编码
+func (b *Builder) encodeRowCursor(row reflect.Value) (string, error) {
+	values := make([]interface{}, len(b.keysetCols))
+	for i, key := range b.keysetCols {
+		value, ok := keyValueOf(row, keyColumnName(key.column))
+		if !ok {
+			return "", errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgChunkKeyNotFound, key.column)
+		}
+		values[i] = value
+	}
+	return encodeCursor(b.keysetColumnNames(), values)
+}
+
+// ==================== 游标编解码 ====================
+
+// encodeCursor base64url(JSON) + "." + base64url(HMAC-SHA256)
+func encodeCursor(columns []string, values []interface{}) (string, error) {
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			encoded[i] = map[string]string{cursorTimeKey: t.Format(time.RFC3339Nano)}
+			continue
+		}
+		if raw, ok := value.([]byte); ok {
+			value = string(raw)
+		}
+		encoded[i] = value
+	}
+
+	data, err := json.Marshal(cursorPayload{Columns: columns, Values: encoded})
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(signCursor(body)), nil
+}
+
+// decodeCursor 校验签名并还原游标内容
+func decodeCursor(cursor string) (*cursorPayload, error) {
+	invalid := errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgInvalidCursor)
+
+	body, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, invalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, signCursor(body)) {
+		return nil, invalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, invalid
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var payload cursorPayload
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, invalid
+	}
+
+	for i, value := range payload.Values {
+		switch v := value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				payload.Values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				payload.Values[i] = f
+			}
+		case map[string]interface{}:
+			s, _ := v[cursorTimeKey].(string)
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, invalid
+			}
+			payload.Values[i] = t
+		}
+	}
+	return &payload, nil
+}
+
+func signCursor(body string) []byte {
+	cursorSecretMu.RLock()
+	defer cursorSecretMu.RUnlock()
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+func reverseSlice(v reflect.Value) {
+	swap := reflect.Swapper(v.Interface())
+	for i, j := 0, v.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
+
+func cloneKeysetColumns(src []keysetColumn) []keysetColumn {
+	if src == nil {
+		return nil
+	}
+	dst := make([]keysetColumn, len(src))
+	copy(dst, src)
+	return dst
+}
@@ -0,0 +1,122 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 17:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 17:00:00
+ * @FilePath: \go-sqlbuilder\builder_keyset_test.go
+ * @Description: 游标分页测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderKeysetSQL 测试多列游标条件与排序
+func TestBuilderKeysetSQL(t *testing.T) {
+	base := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("posts").
+		Where("status", "=", "published").
+		OrWhere("featured", "=", true).
+		OrderByKeyDesc("created_at").
+		OrderByKey("id")
+
+	cursor, err := base.EncodeCursor("2025-01-01", 10)
+	require.NoError(t, err)
+
+	sql, args := base.Clone().After(cursor).Limit(20).ToSQL()
+	assert.Equal(t, `SELECT * FROM "posts" WHERE ("status" = $1 OR "featured" = $2) AND (("created_at" < $3) OR ("created_at" = $4 AND "id" > $5)) ORDER BY "created_at" DESC, "id" ASC LIMIT 20`, sql)
+	assert.Equal(t, []interface{}{"published", true, "2025-01-01", "2025-01-01", int64(10)}, args)
+
+	sql, _ = base.Clone().Before(cursor).Limit(20).ToSQL()
+	assert.Equal(t, `SELECT * FROM "posts" WHERE ("status" = $1 OR "featured" = $2) AND (("created_at" > $3) OR ("created_at" = $4 AND "id" < $5)) ORDER BY "created_at" ASC, "id" DESC LIMIT 20`, sql)
+
+	t.Logf("✓ 游标分页SQL: %s", sql)
+}
+
+// TestCursorRoundTrip 测试游标编解码与防篡改
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err := encodeCursor([]string{"created_at", "id"}, []interface{}{at, int64(9007199254740993)})
+	require.NoError(t, err)
+
+	payload, err := decodeCursor(cursor)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(payload.Values[0].(time.Time)))
+	assert.Equal(t, int64(9007199254740993), payload.Values[1])
+
+	_, err = decodeCursor(cursor[:len(cursor)-2] + "xx")
+	assert.Error(t, err)
+	_, err = decodeCursor("not-a-cursor")
+	assert.Error(t, err)
+
+	t.Logf("✓ 游标: %s", cursor)
+}
+
+// TestBuilderCursorPaginate 测试在SQLite上前后翻页
+func TestBuilderCursorPaginate(t *testing.T) {
+	db := newSQLiteDB(t)
+	query := func() *Builder {
+		return newSQLiteBuilder(t, db).Table("users").OrderByKey("status").OrderByKey("id")
+	}
+
+	var first []keysetUser
+	page, err := query().CursorPaginate(&first, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Carol"}, userNames(first))
+	assert.True(t, page.HasNext)
+	assert.False(t, page.HasPrev)
+	assert.Empty(t, page.PrevCursor)
+
+	var second []keysetUser
+	page, err = query().After(page.NextCursor).CursorPaginate(&second, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bob"}, userNames(second))
+	assert.False(t, page.HasNext)
+	assert.True(t, page.HasPrev)
+
+	var back []keysetUser
+	page, err = query().Before(page.PrevCursor).CursorPaginate(&back, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Carol"}, userNames(back))
+	assert.True(t, page.HasNext)
+	assert.False(t, page.HasPrev)
+
+	// 排序列不一致的游标被拒绝
+	var other []keysetUser
+	_, err = newSQLiteBuilder(t, db).Table("users").OrderByKey("id").After(page.NextCursor).CursorPaginate(&other, 2)
+	assert.Error(t, err)
+
+	// 伪造的游标在 After 时记录错误，Get / ToSQL 不会退化为返回第一页
+	forged := query().After("garbage.garbage")
+	assert.True(t, errors.IsErrorCode(forged.Err(), errors.ErrorCodeInvalidInput))
+	assert.Error(t, forged.Get(&other))
+	sql, _ := forged.ToSQL()
+	assert.Contains(t, sql, "1 = 0")
+	assert.Error(t, newSQLiteBuilder(t, db).Table("users").After("garbage.garbage").OrderByKey("id").Err())
+
+	t.Logf("✓ 游标分页: %v -> %v -> %v", userNames(first), userNames(second), userNames(back))
+}
+
+type keysetUser struct {
+	ID     int64  `db:"id"`
+	Name   string `db:"name"`
+	Status string `db:"status"`
+}
+
+func userNames(users []keysetUser) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	return names
+}
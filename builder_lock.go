@@ -0,0 +1,112 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 15:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 15:00:00
+ * @FilePath: \go-sqlbuilder\builder_lock.go
+ * @Description: 悲观锁 - FOR UPDATE / FOR SHARE / SKIP LOCKED / NOWAIT
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+)
+
+// 锁模式
+const (
+	lockForUpdate = "UPDATE"
+	lockForShare  = "SHARE"
+)
+
+// 锁等待策略
+const (
+	lockSkipLocked = "SKIP LOCKED"
+	lockNoWait     = "NOWAIT"
+)
+
+// LockForUpdate 排他锁: SELECT ... FOR UPDATE，需在事务中使用
+func (b *Builder) LockForUpdate() *Builder {
+	b = b.derive()
+	b.lockMode = lockForUpdate
+	return b
+}
+
+// LockForShare 共享锁: SELECT ... FOR SHARE
+func (b *Builder) LockForShare() *Builder {
+	b = b.derive()
+	b.lockMode = lockForShare
+	return b
+}
+
+// SkipLocked 跳过已被锁定的行，适用于任务队列抢占
+func (b *Builder) SkipLocked() *Builder {
+	b = b.derive()
+	b.lockWait = lockSkipLocked
+	return b
+}
+
+// NoWait 行已被锁定时立即报错而非等待
+func (b *Builder) NoWait() *Builder {
+	b = b.derive()
+	b.lockWait = lockNoWait
+	return b
+}
+
+// Of 仅锁定指定表的行: FOR UPDATE OF t1, t2 (SQL Server 忽略)
+func (b *Builder) Of(tables ...string) *Builder {
+	b = b.derive()
+	if !b.checkTables(tables...) {
+		return b
+	}
+	b.lockOf = append(b.lockOf, tables...)
+	return b
+}
+
+// buildLock 生成末尾的锁定子句 (含前导空格)
+// SQLite 无行级锁，SQL Server 使用表提示 (见 buildTableHint)
+func (b *Builder) buildLock() string {
+	if b.lockMode == "" {
+		return ""
+	}
+	switch b.GetDialect() {
+	case constant.DialectSQLite, constant.DialectSQLServer:
+		return ""
+	}
+
+	var sql strings.Builder
+	sql.WriteString(" FOR ")
+	sql.WriteString(b.lockMode)
+	if len(b.lockOf) > 0 {
+		sql.WriteString(" OF ")
+		sql.WriteString(strings.Join(b.quoteIdentifiers(b.lockOf), ", "))
+	}
+	if b.lockWait != "" {
+		sql.WriteString(" ")
+		sql.WriteString(b.lockWait)
+	}
+	return sql.String()
+}
+
+// buildTableHint SQL Server 表提示 (含前导空格): WITH (UPDLOCK, ROWLOCK, READPAST)
+// 仅作用于主表，派生表不加提示
+func (b *Builder) buildTableHint() string {
+	if b.lockMode == "" || b.fromSub != nil || b.GetDialect() != constant.DialectSQLServer {
+		return ""
+	}
+
+	hints := []string{"UPDLOCK", "ROWLOCK"}
+	if b.lockMode == lockForShare {
+		hints = []string{"HOLDLOCK", "ROWLOCK"}
+	}
+	switch b.lockWait {
+	case lockSkipLocked:
+		hints = append(hints, "READPAST")
+	case lockNoWait:
+		hints = append(hints, "NOWAIT")
+	}
+	return " WITH (" + strings.Join(hints, ", ") + ")"
+}
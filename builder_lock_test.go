@@ -0,0 +1,65 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 15:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 15:00:00
+ * @FilePath: \go-sqlbuilder\builder_lock_test.go
+ * @Description: 悲观锁子句测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuilderLockDialects 测试各方言的锁定子句
+func TestBuilderLockDialects(t *testing.T) {
+	build := func(dialect string) string {
+		sql, _ := (&Builder{ctx: context.Background()}).
+			WithDialect(dialect).
+			Table("jobs").
+			Where("status", "=", "pending").
+			OrderBy("id").
+			Limit(10).
+			LockForUpdate().
+			SkipLocked().
+			ToSQL()
+		return sql
+	}
+
+	assert.Equal(t, "SELECT * FROM `jobs` WHERE `status` = ? ORDER BY `id` ASC LIMIT 10 FOR UPDATE SKIP LOCKED", build("mysql"))
+	assert.Equal(t, `SELECT * FROM "jobs" WHERE "status" = $1 ORDER BY "id" ASC LIMIT 10 FOR UPDATE SKIP LOCKED`, build("postgres"))
+	assert.Equal(t, `SELECT * FROM "jobs" WHERE "status" = ? ORDER BY "id" ASC LIMIT 10`, build("sqlite"))
+	assert.Equal(t, "SELECT * FROM [jobs] WITH (UPDLOCK, ROWLOCK, READPAST) WHERE [status] = @p1 ORDER BY [id] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", build("sqlserver"))
+
+	t.Logf("✓ FOR UPDATE SKIP LOCKED 方言渲染正确")
+}
+
+// TestBuilderLockShareOfNoWait 测试 FOR SHARE OF ... NOWAIT
+func TestBuilderLockShareOfNoWait(t *testing.T) {
+	sql, _ := (&Builder{ctx: context.Background()}).
+		WithDialect("postgres").
+		Table("orders").As("o").
+		Join("users u", "u.id = o.user_id").
+		LockForShare().
+		Of("o").
+		NoWait().
+		ToSQL()
+	assert.Equal(t, `SELECT * FROM "orders" AS "o" INNER JOIN users u ON u.id = o.user_id FOR SHARE OF "o" NOWAIT`, sql)
+
+	msSQL, _ := (&Builder{ctx: context.Background()}).
+		WithDialect("sqlserver").
+		Table("orders").
+		LockForShare().
+		NoWait().
+		ToSQL()
+	assert.Equal(t, "SELECT * FROM [orders] WITH (HOLDLOCK, ROWLOCK, NOWAIT)", msSQL)
+
+	t.Logf("✓ FOR SHARE SQL: %s", sql)
+}
@@ -0,0 +1,61 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_param.go
+ * @Description: 将 query.Param 应用到原生 Builder
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/query"
+)
+
+// ApplyParam 将 query.Param 的过滤树、HAVING、排序、去重、查询字段与分页应用到构建器
+// 过滤条件以 AND 追加到已有条件之后，OR 链与过滤组整体加括号；HavingClauses 按原生片段追加
+// 安全模式下字段名与操作符按 Where / OrderBy 相同的规则校验，排序方向与 GormScope 一致 (空值为 ASC，非法值记录错误)
+// 分页按 Pagination() 追加 LIMIT / OFFSET: NewParam() 默认 PageSize 为 10，不需要分页时将 PageSize 置 0
+func (b *Builder) ApplyParam(param *query.Param) *Builder {
+	if param == nil {
+		return b
+	}
+	b = b.derive()
+
+	tree := param.FilterTree()
+	for _, filter := range tree.Filters {
+		b.addFilterCondition(string(constant.LOGIC_AND), filter.Field, string(filter.Operator), filter.Value)
+	}
+	for _, group := range tree.Groups {
+		b = b.addFilterGroup(string(constant.LOGIC_AND), group)
+	}
+
+	for _, clause := range param.HavingClauses {
+		b = b.HavingRaw(clause)
+	}
+	for _, order := range param.Orders {
+		direction, err := order.Direction()
+		if err != nil {
+			b.reject(err)
+			continue
+		}
+		if direction == constant.OrderDESC {
+			b = b.OrderByDesc(order.Field)
+		} else {
+			b = b.OrderBy(order.Field)
+		}
+	}
+	if param.Distinct {
+		b = b.Distinct()
+	}
+	if len(param.SelectFields) > 0 {
+		b = b.Select(param.SelectFields...)
+	}
+	if limit, offset := param.Pagination(); limit > 0 {
+		b = b.Limit(int64(limit)).Offset(int64(offset))
+	}
+	return b
+}
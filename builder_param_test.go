@@ -0,0 +1,178 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_param_test.go
+ * @Description: query.Param 应用到 Builder / GORM 及旧过滤模型转换测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/core"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/persist"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/kamalyes/go-sqlbuilder/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type paramUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func newParamGormDB(t *testing.T, db *sqlx.DB) *gorm.DB {
+	t.Helper()
+
+	gormDB, err := gorm.Open(sqlite.Dialector{Conn: db.DB}, &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB
+}
+
+// TestBuilder_ApplyParam 测试将 Param 应用到 Builder
+func TestBuilder_ApplyParam(t *testing.T) {
+	db := newSQLiteDB(t)
+	param := query.NewParam().
+		AddEQ("status", "active").
+		AddOrEQ("name", "Bob").
+		AddFilterGroup(query.NewFilterGroup("OR").
+			AddFilter("age", query.OP_GTE, 25).
+			AddFilter("age", query.OP_IS_NULL, nil)).
+		AddOrderDesc("id").
+		SetSelectFields("id", "name").
+		SetPage(1, 2)
+
+	b := newSQLiteBuilder(t, db).Table("users").Where("id", ">", 0).ApplyParam(param)
+	sql, args := b.ToSQL()
+	assert.Equal(t, `SELECT "id", "name" FROM "users" WHERE "id" > ? AND ("status" = ? OR "name" = ?) AND ("age" >= ? OR "age" IS NULL) ORDER BY "id" DESC LIMIT 2`, sql)
+	assert.Equal(t, []interface{}{0, "active", "Bob", 25}, args)
+
+	var users []paramUser
+	require.NoError(t, b.Get(&users))
+	require.Len(t, users, 2)
+	assert.Equal(t, "Carol", users[0].Name)
+	assert.Equal(t, "Bob", users[1].Name)
+
+	count, err := b.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// 安全模式下按白名单校验
+	b = newSQLiteBuilder(t, db).Table("users").AllowColumns("id", "name").
+		ApplyParam(query.NewParam().AddEQ("password", "x"))
+	assert.True(t, errors.IsErrorCode(b.Err(), errors.ErrorCodeInvalidFieldName))
+	t.Logf("✓ Builder.ApplyParam 测试通过")
+}
+
+// TestParam_GormScope 测试同一个 Param 通过 gorm Scope 执行
+func TestParam_GormScope(t *testing.T) {
+	db := newSQLiteDB(t)
+	gormDB := newParamGormDB(t, db)
+
+	param := query.NewParam().
+		AddEQ("status", "active").
+		AddOrEQ("name", "Bob").
+		AddOrderDesc("id").
+		SetPage(1, 2)
+
+	var names []string
+	require.NoError(t, gormDB.Table("users").Scopes(param.GormScope()).Pluck("name", &names).Error)
+	assert.Equal(t, []string{"Carol", "Bob"}, names)
+
+	var count int64
+	require.NoError(t, gormDB.Table("users").Scopes(param.GormFilterScope()).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+
+	stmt := gormDB.Session(&gorm.Session{DryRun: true}).Table("users").Scopes(param.GormScope()).Find(&[]paramUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `users` WHERE (`status` = ? OR `name` = ?) ORDER BY `id` DESC LIMIT 2", stmt.SQL.String())
+
+	err := gormDB.Table("users").Scopes(query.NewParam().AddEQ("name; --", 1).GormScope()).Count(&count).Error
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+
+	// 排序方向与 ApplyParam 校验一致
+	invalidOrder := query.NewParam().AddOrder("id", "sideways")
+	err = gormDB.Table("users").Scopes(invalidOrder.GormScope()).Find(&[]paramUser{}).Error
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidInput))
+	assert.True(t, errors.IsErrorCode((&Builder{ctx: context.Background()}).Table("users").ApplyParam(invalidOrder).Err(), errors.ErrorCodeInvalidInput))
+
+	// NewParam 默认分页 LIMIT 10，PageSize 置 0 时不分页
+	unpaged := query.NewParam().AddOrder("id", "")
+	unpaged.PageSize = 0
+	stmt = gormDB.Session(&gorm.Session{DryRun: true}).Table("users").Scopes(unpaged.GormScope()).Find(&[]paramUser{}).Statement
+	assert.Equal(t, "SELECT * FROM `users` ORDER BY `id` ASC", stmt.SQL.String())
+	sql, _ := (&Builder{ctx: context.Background()}).Table("users").ApplyParam(unpaged).ToSQL()
+	assert.Equal(t, "SELECT * FROM users ORDER BY id ASC", sql)
+	sql, _ = (&Builder{ctx: context.Background()}).Table("users").ApplyParam(query.NewParam()).ToSQL()
+	assert.Equal(t, "SELECT * FROM users LIMIT 10", sql)
+	t.Logf("✓ Param.GormScope 测试通过")
+}
+
+// TestParam_LegacyConverters 测试 core / repository / persist 过滤模型转换为 Param
+func TestParam_LegacyConverters(t *testing.T) {
+	db := newSQLiteDB(t)
+	gormDB := newParamGormDB(t, db)
+	newBase := func() *Builder { return (&Builder{ctx: context.Background()}).Table("users") }
+
+	coreParam, err := (&core.QueryCondition{
+		Filters: []core.Filter{
+			{Field: "status", Operator: constant.OP_EQ, Value: "active"},
+			{Field: "age", Operator: "gte", Value: 18},
+		},
+		Orders:     []core.OrderBy{{Field: "id", Order: "desc"}},
+		Pagination: &core.Pagination{Page: 1, PageSize: 10, Limit: 10},
+	}).ToParam()
+	require.NoError(t, err)
+	sql, args := newBase().ApplyParam(coreParam).ToSQL()
+	assert.Equal(t, "SELECT * FROM users WHERE status = ? AND age >= ? ORDER BY id DESC LIMIT 10", sql)
+	assert.Equal(t, []interface{}{"active", 18}, args)
+
+	_, err = core.FiltersToParam([]core.Filter{{Field: "age", Operator: "~", Value: 1}})
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidOperator))
+
+	repoParam, err := repository.NewQuery().
+		AddFilters(repository.NewInFilter("id", 1, 2), repository.NewBetweenFilter("age", 20, 40)).
+		AddOrder("name", "ASC").
+		ToParam()
+	require.NoError(t, err)
+	sql, args = newBase().ApplyParam(repoParam).ToSQL()
+	assert.Equal(t, "SELECT * FROM users WHERE id IN (?,?) AND age BETWEEN ? AND ? ORDER BY name ASC", sql)
+	assert.Equal(t, []interface{}{1, 2, 20, 40}, args)
+
+	persistParam, err := persist.ToParam(persist.Filters{
+		persist.NewEqFilter("status", "active"),
+		persist.NewInFilter("id", []int64{1, 3}),
+		persist.NewRangeFilter("age", 18, 40),
+		persist.NewPrefixFilter("name", "A"),
+	}, persist.NewOrder("id", true))
+	require.NoError(t, err)
+
+	var users []paramUser
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").ApplyParam(persistParam).Get(&users))
+	require.Len(t, users, 1)
+	assert.Equal(t, "Alice", users[0].Name)
+
+	var names []string
+	require.NoError(t, gormDB.Table("users").Scopes(persistParam.GormScope()).Pluck("name", &names).Error)
+	assert.Equal(t, []string{"Alice"}, names)
+
+	// Param 作为 persist.Filter 使用
+	var count int64
+	filter := persist.NewParamFilter(query.NewParam().AddEQ("status", "active"))
+	require.NoError(t, filter.Where(gormDB.Table("users")).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	_, err = persist.ToParam(persist.Filters{persist.NewOriginalWhereFilter("1 = 1")})
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeUnsupported))
+	t.Logf("✓ 旧过滤模型转换测试通过")
+}
@@ -0,0 +1,231 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 13:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 13:00:00
+ * @FilePath: \go-sqlbuilder\builder_returning.go
+ * @Description: 写操作返回行 - RETURNING / OUTPUT，MySQL 回退为 LastInsertId + 回查
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// Returning 指定 INSERT / UPDATE / DELETE / UPSERT 返回的列，配合 ExecReturning 使用
+// PostgreSQL/SQLite 渲染为 RETURNING，SQL Server 渲染为 OUTPUT
+func (b *Builder) Returning(columns ...string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(columns...) {
+		return b
+	}
+	b.returningCols = append(b.returningCols, columns...)
+	return b
+}
+
+// ReturningKey 指定回退回查使用的主键列，默认 id
+// 仅在不支持 RETURNING 的方言 (MySQL) 中生效: INSERT 以 LastInsertId 作为该列的值回查，UPDATE 按该列锁定并回查
+func (b *Builder) ReturningKey(column string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.returningKey = column
+	return b
+}
+
+// ExecReturning 执行写操作并将返回行扫描到 dest
+// dest 为切片指针时接收全部行，否则接收第一行 (无返回行时为 sql.ErrNoRows)
+// 未调用 Returning 时返回全部列
+// 不支持 RETURNING 的方言 (MySQL) 回退为额外查询，主键列由 ReturningKey 指定 (默认 id):
+// INSERT 按 LastInsertId 回查 (要求主键自增)；UPDATE 在事务中先按原条件查出主键，
+// 再按主键更新并回查，SET 修改条件列时同样返回被更新的行；DELETE 在事务中锁定主键，回查后按主键删除
+func (b *Builder) ExecReturning(dest interface{}) error {
+	if err := b.Err(); err != nil {
+		return err
+	}
+	query := b.Clone()
+	query.immutable = false
+	if len(query.returningCols) == 0 {
+		query.returningCols = []string{"*"}
+	}
+
+	if !query.supportsReturning() {
+		return query.execReturningFallback(dest)
+	}
+
+	sql, args := query.ToSQL()
+	rows, err := query.adapter.QueryContext(query.ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if isSliceDest(dest) {
+		return scanAll(rows, dest)
+	}
+	return scanOne(rows, dest)
+}
+
+// supportsReturning 当前方言是否支持在写语句中直接返回行
+func (b *Builder) supportsReturning() bool {
+	return b.driver != nil && b.driver.SupportsFeature("returning")
+}
+
+// execReturningFallback 不支持 RETURNING 时通过额外查询获取返回行
+func (b *Builder) execReturningFallback(dest interface{}) error {
+	fetch := func(query *Builder) error {
+		if isSliceDest(dest) {
+			return query.Get(dest)
+		}
+		return query.First(dest)
+	}
+
+	switch b.queryType {
+	case "insert":
+		result, err := b.Exec()
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		return fetch(b.returningQuery(false).Where(b.returningKeyColumn(), "=", id))
+
+	case "update":
+		// 先取主键再更新，避免 SET 修改了条件列后按原条件回查不到被更新的行
+		return b.Transaction(func(tx *Builder) error {
+			key, keys, err := tx.lockReturningKeys()
+			if err != nil || len(keys) == 0 {
+				return noReturningRows(dest, err)
+			}
+			if _, err := tx.byReturningKeys(key, keys).Exec(); err != nil {
+				return err
+			}
+			return fetch(tx.returningQuery(false).WhereIn(key, keys...))
+		})
+
+	case "delete":
+		// 锁定主键后先回查再按主键删除，保证返回行与删除行一致
+		return b.Transaction(func(tx *Builder) error {
+			key, keys, err := tx.lockReturningKeys()
+			if err != nil || len(keys) == 0 {
+				return noReturningRows(dest, err)
+			}
+			if err := fetch(tx.returningQuery(false).WhereIn(key, keys...)); err != nil {
+				return err
+			}
+			_, err = tx.byReturningKeys(key, keys).Exec()
+			return err
+		})
+
+	default:
+		return errors.NewError(errors.ErrorCodeUnsupported, errors.MsgReturningNotSupported)
+	}
+}
+
+// lockReturningKeys 在事务中按原条件查出并锁定待写入行的主键
+func (b *Builder) lockReturningKeys() (string, []interface{}, error) {
+	key := b.returningKeyColumn()
+	keyQuery := b.returningQuery(true)
+	keyQuery.columns = []string{key}
+	// 按实际连接的方言加锁，WithDialect 仅影响 SQL 渲染
+	if b.adapter.GetDialect() == constant.DialectMySQL {
+		keyQuery.lockMode = lockForUpdate
+	}
+	var keys []interface{}
+	if err := keyQuery.Get(&keys); err != nil {
+		return key, nil, err
+	}
+	return key, keys, nil
+}
+
+// byReturningKeys 以主键列表替换原条件的写语句，只写入已锁定的行
+// 原条件含 OR 时直接追加 IN 条件会改变优先级，因此不保留原条件
+func (b *Builder) byReturningKeys(key string, keys []interface{}) *Builder {
+	query := b.Clone()
+	query.immutable = false
+	query.wheres = nil
+	query.args = nil
+	return query.WhereIn(key, keys...)
+}
+
+// noReturningRows 主键查询出错或未命中时的返回值: 切片 dest 为空结果，否则为 sql.ErrNoRows
+func noReturningRows(dest interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	if isSliceDest(dest) {
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+// returningQuery 回查语句: SELECT 返回列 FROM 表 [WHERE 原条件]
+func (b *Builder) returningQuery(keepWhere bool) *Builder {
+	query := &Builder{
+		adapter:    b.adapter,
+		ctx:        b.ctx,
+		timeout:    b.timeout,
+		driver:     b.driver,
+		quoteIdent: b.quoteIdent,
+		table:      b.table,
+		queryType:  "select",
+	}
+	for _, col := range b.returningCols {
+		if col != "*" {
+			query.columns = append(query.columns, col)
+		}
+	}
+	if keepWhere {
+		query.wheres = cloneStrings(b.wheres)
+		query.args = cloneArgs(b.args)
+	}
+	return query
+}
+
+// returningKeyColumn 回退回查使用的主键列
+func (b *Builder) returningKeyColumn() string {
+	if b.returningKey != "" {
+		return b.returningKey
+	}
+	return constant.FieldID
+}
+
+// buildReturning PostgreSQL/SQLite 的 RETURNING 子句 (含前导空格)
+func (b *Builder) buildReturning() string {
+	if len(b.returningCols) == 0 || !b.supportsReturning() || b.GetDialect() == constant.DialectSQLServer {
+		return ""
+	}
+	return " RETURNING " + strings.Join(b.quoteIdentifiers(b.returningCols), ", ")
+}
+
+// buildOutput SQL Server 的 OUTPUT 子句 (含前导空格)，source 为 INSERTED 或 DELETED
+func (b *Builder) buildOutput(source string) string {
+	if len(b.returningCols) == 0 || b.GetDialect() != constant.DialectSQLServer {
+		return ""
+	}
+	cols := b.quoteIdentifiers(b.returningCols)
+	for i, col := range cols {
+		cols[i] = source + "." + col
+	}
+	return " OUTPUT " + strings.Join(cols, ", ")
+}
+
+// isSliceDest dest 是否为切片指针 ([]byte 视为标量)
+func isSliceDest(dest interface{}) bool {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return false
+	}
+	t = t.Elem()
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
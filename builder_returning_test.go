@@ -0,0 +1,164 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 13:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 13:00:00
+ * @FilePath: \go-sqlbuilder\builder_returning_test.go
+ * @Description: RETURNING / OUTPUT 测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderReturningSQL 测试各方言的 RETURNING / OUTPUT 渲染
+func TestBuilderReturningSQL(t *testing.T) {
+	data := map[string]interface{}{"name": "Dave"}
+
+	pgSQL, _ := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("users").Insert(data).Returning("id", "created_at").ToSQL()
+	assert.Equal(t, `INSERT INTO "users" ("name") VALUES ($1) RETURNING "id", "created_at"`, pgSQL)
+
+	updateBuilder, _ := (&Builder{ctx: context.Background()}).WithDialect("sqlite").
+		Table("users").Where("id", "=", 1).Update(data)
+	updateSQL, _ := updateBuilder.Returning("id").ToSQL()
+	assert.Equal(t, `UPDATE "users" SET "name" = ? WHERE "id" = ? RETURNING "id"`, updateSQL)
+
+	msInsert, _ := (&Builder{ctx: context.Background()}).WithDialect("sqlserver").
+		Table("users").Insert(data).Returning("id").ToSQL()
+	assert.Equal(t, "INSERT INTO [users] ([name]) OUTPUT INSERTED.[id] VALUES (@p1)", msInsert)
+
+	msDelete, _ := (&Builder{ctx: context.Background()}).WithDialect("sqlserver").
+		Table("users").Where("id", "=", 1).Delete().Returning("id").ToSQL()
+	assert.Equal(t, "DELETE FROM [users] OUTPUT DELETED.[id] WHERE [id] = @p1", msDelete)
+
+	mysqlSQL, _ := (&Builder{ctx: context.Background()}).WithDialect("mysql").
+		Table("users").Insert(data).Returning("id").ToSQL()
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?)", mysqlSQL)
+
+	t.Logf("✓ RETURNING / OUTPUT 渲染正确")
+}
+
+// TestBuilderExecReturningSQLite 测试在SQLite上通过 RETURNING 扫描写操作结果
+func TestBuilderExecReturningSQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var inserted scanUser
+	err := newSQLiteBuilder(t, db).Table("users").
+		Insert(map[string]interface{}{"name": "Dave", "status": "active"}).
+		Returning("id", "name", "status").
+		ExecReturning(&inserted)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), inserted.ID)
+	assert.Equal(t, "Dave", inserted.Name)
+
+	updateBuilder, err := newSQLiteBuilder(t, db).Table("users").
+		Where("status", "=", "active").
+		Update(map[string]interface{}{"status": "archived"})
+	require.NoError(t, err)
+	var updated []map[string]interface{}
+	require.NoError(t, updateBuilder.Returning("id", "status").ExecReturning(&updated))
+	assert.Len(t, updated, 3)
+	assert.Equal(t, "archived", updated[0]["status"])
+
+	var deletedIDs []int64
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").
+		Where("name", "=", "Bob").Delete().Returning("id").ExecReturning(&deletedIDs))
+	assert.Equal(t, []int64{2}, deletedIDs)
+
+	users := newSQLiteBuilder(t, db).Table("users")
+	id, err := users.InsertGetID(map[string]interface{}{"name": "Eve"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), id)
+
+	// 复用构建器不会累积 RETURNING 列
+	id, err = users.InsertGetID(map[string]interface{}{"name": "Frank"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), id)
+	assert.Empty(t, users.returningCols)
+
+	t.Logf("✓ RETURNING 执行: inserted=%d, updated=%d, deleted=%v", inserted.ID, len(updated), deletedIDs)
+}
+
+// TestBuilderExecReturningFallback 测试不支持 RETURNING 时的 LastInsertId + 回查
+func TestBuilderExecReturningFallback(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	// SQLite 同样接受反引号与 ? 占位符，可用于模拟 MySQL 方言
+	var inserted map[string]interface{}
+	err := newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Insert(map[string]interface{}{"name": "Dave"}).
+		Returning("id", "name").
+		ExecReturning(&inserted)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), inserted["id"])
+	assert.Equal(t, "Dave", inserted["name"])
+
+	// SET 修改了条件列，按主键回查仍返回被更新的行
+	archive := func() *Builder {
+		update, err := newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+			Where("status", "=", "inactive").
+			Update(map[string]interface{}{"status": "archived"})
+		require.NoError(t, err)
+		return update
+	}
+	var updated []map[string]interface{}
+	require.NoError(t, archive().Returning("name", "status").ExecReturning(&updated))
+	require.Len(t, updated, 1)
+	assert.Equal(t, "Bob", updated[0]["name"])
+	assert.Equal(t, "archived", updated[0]["status"])
+
+	var none []map[string]interface{}
+	require.NoError(t, archive().ExecReturning(&none))
+	assert.Empty(t, none)
+	var single scanUser
+	assert.ErrorIs(t, archive().ExecReturning(&single), sql.ErrNoRows)
+
+	// 自定义主键列
+	update, err := newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Where("name", "=", "Dave").
+		Update(map[string]interface{}{"age": 41})
+	require.NoError(t, err)
+	var keyed map[string]interface{}
+	require.NoError(t, update.ReturningKey("name").Returning("name", "age").ExecReturning(&keyed))
+	assert.Equal(t, int64(41), keyed["age"])
+
+	var deleted scanUser
+	require.NoError(t, newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Where("name", "=", "Bob").Delete().ExecReturning(&deleted))
+	assert.Equal(t, "Bob", deleted.Name)
+
+	var missing scanUser
+	err = newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Where("name", "=", "Bob").Delete().ExecReturning(&missing)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 按锁定的主键删除，返回行与删除行一致
+	var removed []map[string]interface{}
+	require.NoError(t, newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Where("name", "=", "Alice").OrWhere("name", "=", "Carol").Delete().
+		Returning("name").ExecReturning(&removed))
+	assert.Len(t, removed, 2)
+	assert.Equal(t, 0, countTxUsers(t, db, "Alice", "Carol"))
+
+	// 写语句只按主键列表限定，不与含 OR 的原条件拼接
+	orUpdate, err := newSQLiteBuilder(t, db).WithDialect("mysql").Table("users").
+		Where("name", "=", "Alice").OrWhere("status", "=", "active").
+		Update(map[string]interface{}{"age": 1})
+	require.NoError(t, err)
+	sqlStr, args := orUpdate.byReturningKeys("id", []interface{}{1, 3}).ToSQL()
+	assert.Equal(t, "UPDATE `users` SET `age` = ? WHERE `id` IN (?,?)", sqlStr)
+	assert.Equal(t, []interface{}{1, 1, 3}, args)
+	assert.Len(t, orUpdate.wheres, 2)
+
+	t.Logf("✓ 回退回查: inserted=%v, deleted=%s", inserted, deleted.Name)
+}
@@ -0,0 +1,126 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 14:00:00
+ * @FilePath: \go-sqlbuilder\builder_safe.go
+ * @Description: 安全模式 - 校验并引用标识符，拒绝未知操作符
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+)
+
+// WithSafeMode 开启/关闭安全模式，适用于字段名、排序字段来自外部输入的场景
+// 安全模式下 Table / As / Select / Where / WhereSubQuery / Having / GroupBy / OrderBy / OrderByKey / WhereCond、
+// 窗口函数、CTE 名、锁定表 (Of) 与 OnConflictSet 等方法按正则 (及白名单) 校验标识符并强制按方言引用，操作符须在 constant.OperatorMap 或
+// constant.CompatOperatorMap 中；校验失败的条件不会加入查询，错误由 Err 及执行方法返回
+// WhereRaw / SelectRaw / HavingRaw / OrderByRaw / Join 等原生方法不做校验，需调用方自行保证安全
+func (b *Builder) WithSafeMode(enable bool) *Builder {
+	b = b.derive()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.safeMode = enable
+	return b
+}
+
+// AllowColumns 设置列白名单并开启安全模式，不在白名单内的列以 ErrorCodeInvalidFieldName 拒绝
+func (b *Builder) AllowColumns(columns ...string) *Builder {
+	b = b.derive()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.safeMode = true
+	b.validator = b.validator.Allow(columns...)
+	return b
+}
+
+// WithIdentifierPattern 自定义标识符正则并开启安全模式，默认为 column.IdentifierPattern
+func (b *Builder) WithIdentifierPattern(pattern *regexp.Regexp) *Builder {
+	b = b.derive()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.safeMode = true
+	b.validator = b.validator.WithPattern(pattern)
+	return b
+}
+
+// IsSafeMode 是否处于安全模式
+func (b *Builder) IsSafeMode() bool {
+	return b.safeMode
+}
+
+// Err 构建过程中记录的第一个校验错误，或当前 UPSERT 语句无法生成的原因
+func (b *Builder) Err() error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.upsertErr()
+}
+
+// ==================== 私有方法 ====================
+
+// validateColumn 安全模式下校验列名，* 与 table.* 视为合法
+func (b *Builder) validateColumn(name string) error {
+	if !b.safeMode || name == "*" {
+		return nil
+	}
+	if table, ok := strings.CutSuffix(name, ".*"); ok {
+		return b.validator.ValidateTable(table)
+	}
+	return b.validator.ValidateName(name)
+}
+
+// validateTable 安全模式下校验表名
+func (b *Builder) validateTable(name string) error {
+	if !b.safeMode {
+		return nil
+	}
+	return b.validator.ValidateTable(name)
+}
+
+// checkColumns 校验列名，失败时记录错误并返回 false
+func (b *Builder) checkColumns(names ...string) bool {
+	for _, name := range names {
+		if err := b.validateColumn(name); err != nil {
+			return b.reject(err)
+		}
+	}
+	return true
+}
+
+// checkTables 校验表名、别名、CTE 名、窗口名等非列标识符 (不受列白名单限制)
+func (b *Builder) checkTables(names ...string) bool {
+	for _, name := range names {
+		if err := b.validateTable(name); err != nil {
+			return b.reject(err)
+		}
+	}
+	return true
+}
+
+// checkOperator 返回规范化后的操作符 (如 eq => =)，与 query 包的渲染一致
+// 无法识别的操作符仅在安全模式下拒绝，否则原样使用
+func (b *Builder) checkOperator(operator string) (string, bool) {
+	op, err := column.ParseOperator(operator)
+	if err == nil {
+		return string(op), true
+	}
+	if b.safeMode {
+		return "", b.reject(err)
+	}
+	return operator, true
+}
+
+// reject 记录第一个错误，始终返回 false
+func (b *Builder) reject(err error) bool {
+	if b.err == nil {
+		b.err = err
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 14:00:00
+ * @FilePath: \go-sqlbuilder\builder_safe_test.go
+ * @Description: 安全模式测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/db"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/kamalyes/go-sqlbuilder/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestSafeMode_QuotesIdentifiers 测试安全模式下强制引用标识符并规范化操作符
+func TestSafeMode_QuotesIdentifiers(t *testing.T) {
+	b := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		WithQuoteIdentifiers(false).
+		WithSafeMode(true).
+		Table("users").
+		Where("u.status", "eq", "active").
+		GroupBy("status").
+		OrderByDesc("created_at")
+
+	require.NoError(t, b.Err())
+	sql, args := b.ToSQL()
+	assert.Equal(t, `SELECT * FROM "users" WHERE "u"."status" = $1 GROUP BY "status" ORDER BY "created_at" DESC`, sql)
+	assert.Equal(t, []interface{}{"active"}, args)
+	t.Logf("✓ 安全模式引用测试通过")
+}
+
+// TestSafeMode_RejectsInjection 测试安全模式拒绝非法标识符与未知操作符
+func TestSafeMode_RejectsInjection(t *testing.T) {
+	base := (&Builder{ctx: context.Background()}).WithDialect("mysql").WithSafeMode(true).Immutable()
+	sub := (&Builder{ctx: context.Background()}).Table("orders").Select("user_id")
+
+	cases := []struct {
+		name string
+		b    *Builder
+		code errors.ErrorCode
+	}{
+		{"order by", base.Table("users").OrderBy("id; DROP TABLE users"), errors.ErrorCodeInvalidFieldName},
+		{"where column", base.Table("users").Where("1=1 OR name", "=", "x"), errors.ErrorCodeInvalidFieldName},
+		{"where operator", base.Table("users").Where("name", "= 1 OR 1 =", "x"), errors.ErrorCodeInvalidOperator},
+		{"table", base.Table("users u; --"), errors.ErrorCodeInvalidFieldName},
+		{"group by", base.Table("users").GroupBy("status", "(SELECT 1)"), errors.ErrorCodeInvalidFieldName},
+		{"having", base.Table("users").Having("COUNT(*)", ">", 1), errors.ErrorCodeInvalidFieldName},
+		{"filter group", base.Table("users").WhereFilterGroup(
+			query.NewFilterGroup("OR").AddFilter("name", "=", "a").AddFilter("name", "SOUNDS LIKE", "b")), errors.ErrorCodeInvalidOperator},
+		{"subquery column", base.Table("users").WhereSubQuery("id) OR 1=1 --", "IN", sub), errors.ErrorCodeInvalidFieldName},
+		{"subquery operator", base.Table("users").OrWhereSubQuery("id", "= 1 OR 1=1 OR id IN", sub), errors.ErrorCodeInvalidOperator},
+		{"alias", base.Table("users").As("u; DROP TABLE x"), errors.ErrorCodeInvalidFieldName},
+		{"from sub alias", base.FromSub(sub, "t) x; --"), errors.ErrorCodeInvalidFieldName},
+		{"window partition", base.Table("users").SelectWindow(RowNumber().PartitionBy("status, (SELECT 1)")), errors.ErrorCodeInvalidFieldName},
+		{"window order", base.Table("users").Window("w", NewWindow().OrderByDesc("id; --")), errors.ErrorCodeInvalidFieldName},
+		{"window frame", base.Table("users").SelectWindow(SumOver("age").OrderBy("id").Frame("ROWS 1) FROM x; --")), errors.ErrorCodeInvalidSQLQuery},
+		{"cte name", base.With("t AS (SELECT 1); --", sub).Table("t"), errors.ErrorCodeInvalidFieldName},
+		{"lock of", base.Table("users").LockForUpdate().Of("users; --"), errors.ErrorCodeInvalidFieldName},
+		{"on conflict set", base.Table("users").Upsert(map[string]interface{}{"id": 1}, []string{"id"}, nil).
+			OnConflictSet("n = 1, id", "1"), errors.ErrorCodeInvalidFieldName},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, errors.IsErrorCode(tc.b.Err(), tc.code), "got %v", tc.b.Err())
+			_, err := tc.b.Exec()
+			assert.Equal(t, tc.b.Err(), err)
+		})
+	}
+
+	// 合法的子查询、别名与窗口仍正常引用
+	ok := base.Table("users").As("u").WhereSubQuery("id", "in", sub).
+		SelectWindow(SumOver("age").PartitionBy("status").OrderBy("id").
+			Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW").As("running"))
+	require.NoError(t, ok.Err())
+	sql, _ := ok.ToSQL()
+	assert.Contains(t, sql, "FROM `users` AS `u` WHERE `id` IN (SELECT `user_id` FROM `orders`)")
+	assert.Contains(t, sql, "SUM(`age`) OVER (PARTITION BY `status` ORDER BY `id` ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS `running`")
+
+	// 不可变模板本身不受影响
+	assert.NoError(t, base.Err())
+	t.Logf("✓ 安全模式拒绝注入测试通过")
+}
+
+// TestSafeMode_AllowColumns 测试列白名单
+func TestSafeMode_AllowColumns(t *testing.T) {
+	b := (&Builder{ctx: context.Background()}).WithDialect("sqlite").
+		AllowColumns("id", "name").
+		Table("users").
+		Where("users.name", "=", "Alice").
+		OrderBy("id")
+	require.NoError(t, b.Err())
+
+	b = b.OrderBy("password")
+	assert.True(t, errors.IsErrorCode(b.Err(), errors.ErrorCodeInvalidFieldName))
+
+	// 原生方法不做校验
+	raw := (&Builder{ctx: context.Background()}).AllowColumns("id").Table("users").WhereRaw("LOWER(name) = ?", "a")
+	assert.NoError(t, raw.Err())
+
+	custom := (&Builder{ctx: context.Background()}).WithIdentifierPattern(regexp.MustCompile(`^[a-z_]+$`)).Table("Users")
+	assert.Error(t, custom.Err())
+	t.Logf("✓ 列白名单测试通过")
+}
+
+// TestSafeMode_Query 测试安全模式下执行查询
+func TestSafeMode_Query(t *testing.T) {
+	sqlxDB := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, sqlxDB).AllowColumns("id", "name", "status", "age")
+
+	var names []string
+	require.NoError(t, b.Table("users").Where("status", "eq", "active").OrderBy("id").Pluck("name", &names))
+	assert.Equal(t, []string{"Alice", "Carol"}, names)
+
+	_, err := newSQLiteBuilder(t, sqlxDB).AllowColumns("id").Table("users").Count("email")
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+
+	_, err = newSQLiteBuilder(t, sqlxDB).AllowColumns("id").Table("users").Max("age")
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+	t.Logf("✓ 安全模式查询测试通过")
+}
+
+// TestSafeMode_Param 测试 query.Param 安全构建
+func TestSafeMode_Param(t *testing.T) {
+	quote := func(s string) string { return "`" + s + "`" }
+
+	param := query.NewParam().AddFilter("status", "eq", "active").AddIn("id", 1, 2).AddOrderDesc("created_at")
+	sql, args, err := param.BuildWhereClauseSafe(column.NewValidator(), quote)
+	require.NoError(t, err)
+	assert.Equal(t, "WHERE `status` = ? AND `id` IN (?,?)", sql)
+	assert.Equal(t, []interface{}{"active", 1, 2}, args)
+
+	_, _, err = query.NewParam().AddEQ("name) OR (1", 1).BuildWhereClauseSafe(nil, quote)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+
+	_, _, err = query.NewParam().AddFilter("name", "REGEXP", ".*").BuildWhereClauseSafe(nil, quote)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidOperator))
+
+	err = query.NewParam().AddEQ("status", 1).AddOrder("id", "DESC, (SELECT 1)").Validate(nil)
+	assert.Error(t, err)
+
+	err = query.NewParam().AddEQ("secret", 1).Validate(column.NewValidator("status"))
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+	t.Logf("✓ Param 安全构建测试通过")
+}
+
+// TestSafeMode_Repository 测试仓储安全模式
+func TestSafeMode_Repository(t *testing.T) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, status TEXT)`).Error)
+	require.NoError(t, gormDB.Exec(`INSERT INTO users (name, status) VALUES ('Alice', 'active'), ('Bob', 'inactive'), ('Carol', 'active')`).Error)
+
+	type user struct {
+		ID     int64
+		Name   string
+		Status string
+	}
+	repo := repository.NewBaseRepository[user](db.NewGormHandler(gormDB), "users").
+		WithSafeMode(column.NewValidator("id", "name", "status"))
+	ctx := context.Background()
+
+	users, err := repo.List(ctx, repository.NewQuery().
+		AddFilter(&repository.Filter{Field: "status", Operator: "eq", Value: "active"}).
+		AddFilter(&repository.Filter{Field: "id", Operator: "NOT IN", Value: []int64{1}}).
+		AddOrder("id", "DESC"))
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Carol", users[0].Name)
+
+	_, err = repo.List(ctx, repository.NewQuery().AddOrder("id; DROP TABLE users", "ASC"))
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+
+	_, err = repo.Count(ctx, &repository.Filter{Field: "name", Operator: "= '' OR 1=1 --", Value: 1})
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidOperator))
+
+	_, err = repo.Count(ctx, repository.NewEqFilter("password", "x"))
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidFieldName))
+	t.Logf("✓ 仓储安全模式测试通过")
+}
@@ -0,0 +1,331 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 11:00:00
+ * @FilePath: \go-sqlbuilder\builder_scan.go
+ * @Description: 结果集扫描 - 按列名映射到结构体/map/标量
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	mapType     = reflect.TypeOf(map[string]interface{}{})
+
+	// structFieldsCache 结构体列映射缓存
+	structFieldsCache sync.Map // map[reflect.Type]map[string][]int
+	// structColumnsCache 结构体有序列缓存
+	structColumnsCache sync.Map // map[reflect.Type][]structColumn
+)
+
+// structColumn 结构体字段对应的列
+type structColumn struct {
+	name  string
+	index []int
+	field reflect.StructField
+}
+
+// scanAll 扫描全部行到切片
+// 支持 *[]T / *[]*T / *[]map[string]interface{} / *[]标量
+func scanAll(rows *sql.Rows, dest interface{}) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
+	}
+
+	sliceVal := destVal.Elem()
+	if sliceVal.Kind() != reflect.Slice {
+		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	elemType := sliceVal.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+
+	// 清空已有内容，保留容量
+	sliceVal.Set(sliceVal.Slice(0, 0))
+
+	for rows.Next() {
+		elem := reflect.New(baseType)
+		if err := scanCurrent(rows, columns, elem); err != nil {
+			return err
+		}
+		if isPtr {
+			sliceVal.Set(reflect.Append(sliceVal, elem))
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, elem.Elem()))
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanOne 扫描第一行，无数据时返回 sql.ErrNoRows
+// 支持 *T / **T / *map[string]interface{} / *标量
+func scanOne(rows *sql.Rows, dest interface{}) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgDestMustBePointer)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	target := destVal
+	// **T: 按需分配
+	if target.Elem().Kind() == reflect.Ptr && !isScalarType(target.Elem().Type()) {
+		if target.Elem().IsNil() {
+			target.Elem().Set(reflect.New(target.Elem().Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	if err := scanCurrent(rows, columns, target); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// scanCurrent 将当前行扫描到 ptr 指向的值
+func scanCurrent(rows *sql.Rows, columns []string, ptr reflect.Value) error {
+	target := ptr.Elem()
+
+	switch {
+	case target.Type() == mapType:
+		return scanMap(rows, columns, target)
+	case target.Kind() == reflect.Struct && !isScalarType(target.Type()):
+		return scanStruct(rows, columns, target)
+	default:
+		if len(columns) != 1 {
+			return errors.NewErrorf(errors.ErrorCodeInvalidInput, "scalar destination expects 1 column, got %d", len(columns))
+		}
+		return rows.Scan(ptr.Interface())
+	}
+}
+
+// scanMap 扫描到 map[string]interface{}，[]byte 转为 string
+func scanMap(rows *sql.Rows, columns []string, target reflect.Value) error {
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return err
+	}
+
+	if target.IsNil() {
+		target.Set(reflect.MakeMapWithSize(mapType, len(columns)))
+	}
+	for i, col := range columns {
+		value := values[i]
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		target.SetMapIndex(reflect.ValueOf(col), reflect.ValueOf(&value).Elem())
+	}
+	return nil
+}
+
+// scanStruct 按列名扫描到结构体，未匹配的列被丢弃，缺失的列保持零值
+func scanStruct(rows *sql.Rows, columns []string, target reflect.Value) error {
+	fields := structFields(target.Type())
+
+	pointers := make([]interface{}, len(columns))
+	for i, col := range columns {
+		index, ok := fields[col]
+		if !ok {
+			index, ok = fields[strings.ToLower(col)]
+		}
+		if !ok {
+			pointers[i] = new(interface{})
+			continue
+		}
+		field, ok := fieldByIndexAlloc(target, index)
+		if !ok {
+			pointers[i] = new(interface{})
+			continue
+		}
+		pointers[i] = field.Addr().Interface()
+	}
+
+	return rows.Scan(pointers...)
+}
+
+// fieldByIndexAlloc 按索引路径取字段，遇到 nil 的嵌入指针时自动分配
+// 未导出的嵌入指针无法分配，返回 false
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, v.CanSet()
+}
+
+// structFields 获取结构体的列名到字段索引路径的映射 (带缓存)
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+	for _, col := range structColumns(t) {
+		if _, exists := fields[col.name]; !exists {
+			fields[col.name] = col.index
+		}
+		lower := strings.ToLower(col.name)
+		if _, exists := fields[lower]; !exists {
+			fields[lower] = col.index
+		}
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// structColumns 获取结构体按声明顺序排列的列 (带缓存)，同名列只保留外层字段
+func structColumns(t reflect.Type) []structColumn {
+	if cached, ok := structColumnsCache.Load(t); ok {
+		return cached.([]structColumn)
+	}
+
+	var columns []structColumn
+	collectStructColumns(t, nil, "", make(map[string]bool), &columns)
+	structColumnsCache.Store(t, columns)
+	return columns
+}
+
+// collectStructColumns 递归收集字段，外层字段优先于嵌入字段
+func collectStructColumns(t reflect.Type, parent []int, prefix string, seen map[string]bool, columns *[]structColumn) {
+	type embedded struct {
+		t      reflect.Type
+		index  []int
+		prefix string
+	}
+	var nested []embedded
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		name, skip := column.NameOf(field.Name, field.Tag)
+		if skip {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		// 嵌入结构体 / gorm embedded 字段展开
+		if fieldType.Kind() == reflect.Struct && !isScalarType(fieldType) {
+			if field.Anonymous && !column.HasExplicitName(field.Tag) {
+				nested = append(nested, embedded{t: fieldType, index: index, prefix: prefix})
+				continue
+			}
+			if gormEmbedded, embeddedPrefix := column.EmbeddedOf(field.Tag); gormEmbedded {
+				nested = append(nested, embedded{t: fieldType, index: index, prefix: prefix + embeddedPrefix})
+				continue
+			}
+		}
+
+		if !field.IsExported() || !isColumnType(field) {
+			continue
+		}
+
+		colName := prefix + name
+		if seen[strings.ToLower(colName)] {
+			continue
+		}
+		seen[strings.ToLower(colName)] = true
+		*columns = append(*columns, structColumn{name: colName, index: index, field: field})
+	}
+
+	for _, e := range nested {
+		collectStructColumns(e.t, e.index, e.prefix, seen, columns)
+	}
+}
+
+// isScalarType 是否作为单列值扫描 (time.Time / sql.Null* / 实现了 sql.Scanner 的类型)
+func isScalarType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+	return t.Kind() != reflect.Struct && t.Kind() != reflect.Map
+}
+
+// isColumnType 字段是否映射为单列: 基础类型、[]byte、time.Time、实现 sql.Scanner / driver.Valuer 的类型
+// 或带 gorm serializer 的字段；其余结构体、切片、map 视为关联 (has many / belongs to 等)，不映射为列
+func isColumnType(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || reflect.PointerTo(t).Implements(scannerType) || reflect.PointerTo(t).Implements(valuerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array:
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+	default:
+		return true
+	}
+	return strings.Contains(strings.ToLower(field.Tag.Get("gorm")), "serializer:")
+}
@@ -0,0 +1,147 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 11:00:00
+ * @FilePath: \go-sqlbuilder\builder_scan_test.go
+ * @Description: 结果集扫描测试 - 基于内存SQLite
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "gorm.io/driver/sqlite"
+)
+
+// newSQLiteDB 创建内存SQLite并初始化users表
+func newSQLiteDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		email TEXT,
+		age INTEGER,
+		status TEXT,
+		created_at DATETIME
+	)`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO users (name, email, age, status, created_at) VALUES
+		('Alice', 'alice@example.com', 30, 'active', '2025-01-01 00:00:00'),
+		('Bob', NULL, 25, 'inactive', '2025-01-02 00:00:00'),
+		('Carol', 'carol@example.com', NULL, 'active', '2025-01-03 00:00:00')`)
+	require.NoError(t, err)
+
+	return db
+}
+
+// newSQLiteBuilder 基于已有连接创建新的构建器
+func newSQLiteBuilder(t *testing.T, db *sqlx.DB) *Builder {
+	t.Helper()
+
+	builder, err := New(db)
+	require.NoError(t, err)
+	return builder
+}
+
+type scanBase struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+type scanUser struct {
+	scanBase
+	Age    *int
+	Email  sql.NullString `json:"email"`
+	Name   string         `db:"name"`
+	Ignore string         `db:"-"`
+}
+
+// TestBuilderGetStruct 测试按列名扫描到结构体切片
+func TestBuilderGetStruct(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var users []scanUser
+	err := newSQLiteBuilder(t, db).Table("users").Select("name", "age", "id", "email", "created_at", "status").OrderBy("id").Get(&users)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+
+	assert.Equal(t, int64(1), users[0].ID)
+	assert.Equal(t, "Alice", users[0].Name)
+	assert.Equal(t, 30, *users[0].Age)
+	assert.Equal(t, "alice@example.com", users[0].Email.String)
+	assert.False(t, users[0].CreatedAt.IsZero())
+	assert.False(t, users[1].Email.Valid)
+	assert.Nil(t, users[2].Age)
+
+	t.Logf("✓ Scanned %d users", len(users))
+}
+
+// TestBuilderGetPointerSlice 测试 *[]*T 与 []map 目标
+func TestBuilderGetPointerSlice(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var users []*scanUser
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Where("status", "=", "active").Get(&users))
+	require.Len(t, users, 2)
+	assert.Equal(t, "Carol", users[1].Name)
+
+	var records []map[string]interface{}
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Select("id", "name").Get(&records))
+	require.Len(t, records, 3)
+	assert.Equal(t, "Alice", records[0]["name"])
+}
+
+// TestBuilderFirst 测试First扫描到结构体/map/标量
+func TestBuilderFirst(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var user scanUser
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Where("name", "=", "Bob").First(&user))
+	assert.Equal(t, int64(2), user.ID)
+
+	var record map[string]interface{}
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Where("id", "=", 3).First(&record))
+	assert.Equal(t, "Carol", record["name"])
+
+	var name string
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Select("name").Where("id", "=", 1).First(&name))
+	assert.Equal(t, "Alice", name)
+
+	err := newSQLiteBuilder(t, db).Table("users").Where("id", "=", 99).First(&user)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestBuilderCountKeepsState 测试Count不修改构建器状态
+func TestBuilderCountKeepsState(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	builder := newSQLiteBuilder(t, db).Table("users").Select("id", "name").Where("status", "=", "active").OrderBy("id").Limit(1)
+	before, _ := builder.ToSQL()
+
+	count, err := builder.Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	after, _ := builder.ToSQL()
+	assert.Equal(t, before, after)
+
+	var users []scanUser
+	require.NoError(t, builder.Get(&users))
+	assert.Len(t, users, 1)
+}
@@ -0,0 +1,135 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 15:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 15:00:00
+ * @FilePath: \go-sqlbuilder\builder_subquery.go
+ * @Description: 子查询 / EXISTS / UNION 支持
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unionClause UNION 子句
+type unionClause struct {
+	all   bool
+	query *Builder
+}
+
+// ==================== WHERE 子查询 ====================
+
+// WhereExists WHERE EXISTS (子查询)
+func (b *Builder) WhereExists(sub *Builder) *Builder {
+	sql, args := b.embed(sub)
+	return b.WhereRaw(fmt.Sprintf("EXISTS (%s)", sql), args...)
+}
+
+// WhereNotExists WHERE NOT EXISTS (子查询)
+func (b *Builder) WhereNotExists(sub *Builder) *Builder {
+	sql, args := b.embed(sub)
+	return b.WhereRaw(fmt.Sprintf("NOT EXISTS (%s)", sql), args...)
+}
+
+// OrWhereExists OR EXISTS (子查询)
+func (b *Builder) OrWhereExists(sub *Builder) *Builder {
+	sql, args := b.embed(sub)
+	return b.OrWhereRaw(fmt.Sprintf("EXISTS (%s)", sql), args...)
+}
+
+// WhereSubQuery 列与子查询比较，如 WhereSubQuery("id", "IN", sub) / WhereSubQuery("price", ">", avgSub)
+// 安全模式下校验列名与操作符
+func (b *Builder) WhereSubQuery(column, operator string, sub *Builder) *Builder {
+	return b.addSubQueryWhere("AND", column, operator, sub)
+}
+
+// OrWhereSubQuery OR 列与子查询比较
+func (b *Builder) OrWhereSubQuery(column, operator string, sub *Builder) *Builder {
+	return b.addSubQueryWhere("OR", column, operator, sub)
+}
+
+func (b *Builder) addSubQueryWhere(boolean, column, operator string, sub *Builder) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	op, ok := b.checkOperator(operator)
+	if !ok {
+		return b
+	}
+	sql, args := b.embed(sub)
+	return b.appendWhere(boolean, fmt.Sprintf("%s %s (%s)", b.quoteIdentifier(column), strings.ToUpper(op), sql), args...)
+}
+
+// WhereInSub IN (子查询)
+func (b *Builder) WhereInSub(column string, sub *Builder) *Builder {
+	return b.WhereSubQuery(column, "IN", sub)
+}
+
+// WhereNotInSub NOT IN (子查询)
+func (b *Builder) WhereNotInSub(column string, sub *Builder) *Builder {
+	return b.WhereSubQuery(column, "NOT IN", sub)
+}
+
+// ==================== FROM 子查询 ====================
+
+// FromSub 以子查询作为数据源: SELECT ... FROM (子查询) AS alias
+func (b *Builder) FromSub(sub *Builder, alias string) *Builder {
+	b = b.derive()
+	if !b.checkTables(alias) {
+		return b
+	}
+	b.fromSub = sub.Clone()
+	b.table = ""
+	b.tableAlias = alias
+	b.queryType = "select"
+	return b
+}
+
+// SubQuery 将当前查询包装为派生表，返回以其为数据源的新构建器
+func (b *Builder) SubQuery(alias string) *Builder {
+	return &Builder{
+		adapter:    b.adapter,
+		ctx:        b.ctx,
+		timeout:    b.timeout,
+		driver:     b.driver,
+		quoteIdent: b.quoteIdent,
+		immutable:  b.immutable,
+		fromSub:    b.Clone(),
+		tableAlias: alias,
+		queryType:  "select",
+	}
+}
+
+// ==================== UNION ====================
+
+// Union UNION 去重合并，外层的 ORDER BY / LIMIT 作用于整个结果集
+func (b *Builder) Union(query *Builder) *Builder {
+	return b.addUnion(false, query)
+}
+
+// UnionAll UNION ALL 合并
+func (b *Builder) UnionAll(query *Builder) *Builder {
+	return b.addUnion(true, query)
+}
+
+func (b *Builder) addUnion(all bool, query *Builder) *Builder {
+	b = b.derive()
+	b.unions = append(b.unions, unionClause{all: all, query: query.Clone()})
+	return b
+}
+
+// embed 生成嵌入外层查询的子查询SQL (保持 ? 占位符，由外层统一改写)
+// 子查询未指定方言时沿用外层方言
+func (b *Builder) embed(sub *Builder) (string, []interface{}) {
+	if sub.driver == nil && b.driver != nil {
+		sub = sub.Clone()
+		sub.driver = b.driver
+		sub.quoteIdent = b.quoteIdent
+	}
+	return sub.build()
+}
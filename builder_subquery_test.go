@@ -0,0 +1,120 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-15 15:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-15 15:00:00
+ * @FilePath: \go-sqlbuilder\builder_subquery_test.go
+ * @Description: 子查询 / UNION 测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderWhereExists 测试EXISTS子查询参数顺序
+func TestBuilderWhereExists(t *testing.T) {
+	sub := (&Builder{ctx: context.Background()}).
+		Table("orders").
+		SelectRaw("1").
+		WhereRaw("orders.user_id = users.id").
+		Where("orders.amount", ">", 100)
+
+	sql, args := (&Builder{ctx: context.Background()}).
+		Table("users").
+		Where("status", "=", "active").
+		WhereExists(sub).
+		Where("age", ">", 18).
+		ToSQL()
+
+	assert.Equal(t, "SELECT * FROM users WHERE status = ? AND EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id AND orders.amount > ?) AND age > ?", sql)
+	assert.Equal(t, []interface{}{"active", 100, 18}, args)
+
+	t.Logf("✓ EXISTS SQL: %s", sql)
+}
+
+// TestBuilderWhereSubQuery 测试IN子查询与方言占位符
+func TestBuilderWhereSubQuery(t *testing.T) {
+	sub := (&Builder{ctx: context.Background()}).
+		Table("orders").
+		Select("user_id").
+		Where("status", "=", "paid")
+
+	sql, args := (&Builder{ctx: context.Background()}).
+		WithDialect("postgres").
+		Table("users").
+		Where("tenant_id", "=", 7).
+		WhereInSub("id", sub).
+		ToSQL()
+
+	assert.Equal(t, `SELECT * FROM "users" WHERE "tenant_id" = $1 AND "id" IN (SELECT "user_id" FROM "orders" WHERE status = $2)`, sql)
+	assert.Equal(t, []interface{}{7, "paid"}, args)
+}
+
+// TestBuilderFromSub 测试FROM派生表及JOIN/HAVING参数顺序
+func TestBuilderFromSub(t *testing.T) {
+	inner := (&Builder{ctx: context.Background()}).
+		Table("orders").
+		SelectRaw("user_id, SUM(amount) AS total, ? AS tag", "x").
+		Where("created_at", ">=", "2025-01-01").
+		GroupBy("user_id")
+
+	sql, args := inner.SubQuery("t").
+		Select("t.user_id", "t.total").
+		Join("users u", "u.id = t.user_id AND u.level > ?", 3).
+		Where("t.total", ">", 500).
+		ToSQL()
+
+	assert.Equal(t, "SELECT t.user_id, t.total FROM (SELECT user_id, SUM(amount) AS total, ? AS tag FROM orders WHERE created_at >= ? GROUP BY user_id) AS t INNER JOIN users u ON u.id = t.user_id AND u.level > ? WHERE t.total > ?", sql)
+	assert.Equal(t, []interface{}{"x", "2025-01-01", 3, 500}, args)
+}
+
+// TestBuilderUnion 测试UNION/UNION ALL
+func TestBuilderUnion(t *testing.T) {
+	archived := (&Builder{ctx: context.Background()}).
+		Table("archived_users").
+		Select("id", "name").
+		Where("deleted", "=", 0)
+
+	guests := (&Builder{ctx: context.Background()}).
+		Table("guests").
+		Select("id", "name")
+
+	sql, args := (&Builder{ctx: context.Background()}).
+		Table("users").
+		Select("id", "name").
+		Where("status", "=", "active").
+		Union(archived).
+		UnionAll(guests).
+		OrderBy("name").
+		Limit(20).
+		ToSQL()
+
+	assert.Equal(t, "SELECT id, name FROM users WHERE status = ? UNION SELECT id, name FROM archived_users WHERE deleted = ? UNION ALL SELECT id, name FROM guests ORDER BY name ASC LIMIT 20", sql)
+	assert.Equal(t, []interface{}{"active", 0}, args)
+}
+
+// TestBuilderSubQueryExecute 测试子查询在SQLite上执行
+func TestBuilderSubQueryExecute(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	sub := newSQLiteBuilder(t, db).Table("users").Select("id").Where("status", "=", "active")
+
+	var names []string
+	err := newSQLiteBuilder(t, db).
+		Table("users").
+		Select("name").
+		WhereInSub("id", sub).
+		UnionAll(newSQLiteBuilder(t, db).Table("users").Select("name").Where("age", "=", 25)).
+		OrderBy("name").
+		Get(&names)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, names)
+}
@@ -0,0 +1,123 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 14:00:00
+ * @FilePath: \go-sqlbuilder\builder_tx.go
+ * @Description: 事务与基于保存点的嵌套事务
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// savepointPrefix 嵌套事务保存点名称前缀，后接嵌套深度
+const savepointPrefix = "sqlbuilder_sp_"
+
+// Transaction 在事务中执行 fn，fn 返回错误时回滚，panic 时回滚后继续抛出
+// 在事务构建器上再次调用时以 SAVEPOINT 实现嵌套事务，内层失败只回滚到保存点，不影响外层事务
+func (b *Builder) Transaction(fn func(*Builder) error) error {
+	return b.TransactionWithOptions(nil, fn)
+}
+
+// TransactionWithOptions 以指定的隔离级别 / 只读选项开启事务
+// 嵌套调用时事务已开启，opts 被忽略
+func (b *Builder) TransactionWithOptions(opts *sql.TxOptions, fn func(*Builder) error) error {
+	if b.txDepth > 0 {
+		return b.savepoint(fn)
+	}
+
+	tx, err := b.adapter.BeginTx(b.ctx, opts)
+	if errors.IsErrorCode(err, errors.ErrorCodeNestedTransaction) {
+		// 适配器本身已处于事务中 (如 New(*sqlx.Tx))，退化为保存点
+		return b.savepoint(fn)
+	}
+	if err != nil {
+		return err
+	}
+	adapter, ok := tx.(UniversalAdapterInterface)
+	if !ok {
+		_ = tx.Rollback()
+		return errors.NewError(errors.ErrorCodeAdapterNotSupported, errors.MsgAdapterNotSupported)
+	}
+
+	// 事务构建器继承当前查询的完整状态，互不影响
+	txBuilder := b.Clone()
+	txBuilder.adapter = adapter
+	txBuilder.txDepth = 1
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(txBuilder); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// InTransaction 是否处于事务中
+func (b *Builder) InTransaction() bool {
+	return b.txDepth > 0
+}
+
+// savepoint 以保存点执行嵌套事务
+func (b *Builder) savepoint(fn func(*Builder) error) error {
+	depth := b.txDepth
+	if depth == 0 {
+		depth = 1
+	}
+	name := fmt.Sprintf("%s%d", savepointPrefix, depth)
+	create, rollback, release := b.savepointSQL(name)
+
+	if _, err := b.adapter.ExecContext(b.ctx, create); err != nil {
+		return errors.NewErrorf(errors.ErrorCodeNestedTransaction, errors.MsgSavepointFailed, name, err)
+	}
+
+	nested := b.Clone()
+	nested.txDepth = depth + 1
+
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = b.adapter.ExecContext(b.ctx, rollback)
+			panic(r)
+		}
+	}()
+
+	if err := fn(nested); err != nil {
+		if _, rbErr := b.adapter.ExecContext(b.ctx, rollback); rbErr != nil {
+			return errors.NewErrorf(errors.ErrorCodeNestedTransaction, errors.MsgSavepointFailed, name, rbErr)
+		}
+		return err
+	}
+	if release == "" {
+		return nil
+	}
+	if _, err := b.adapter.ExecContext(b.ctx, release); err != nil {
+		return errors.NewErrorf(errors.ErrorCodeNestedTransaction, errors.MsgSavepointFailed, name, err)
+	}
+	return nil
+}
+
+// savepointSQL 按方言生成创建、回滚与释放保存点的语句，SQL Server 无释放语句
+func (b *Builder) savepointSQL(name string) (create, rollback, release string) {
+	dialect := b.GetDialect()
+	if dialect == "" && b.adapter != nil {
+		dialect = normalizeDialect(b.adapter.GetDialect())
+	}
+	if dialect == constant.DialectSQLServer {
+		return "SAVE TRANSACTION " + name, "ROLLBACK TRANSACTION " + name, ""
+	}
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name, "RELEASE SAVEPOINT " + name
+}
@@ -0,0 +1,71 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 18:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 18:00:00
+ * @FilePath: \go-sqlbuilder\builder_tx_retry.go
+ * @Description: 死锁 / 序列化失败时自动重试整个事务
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/middleware"
+)
+
+// defaultTxRetryAttempts 事务默认最大执行次数
+const defaultTxRetryAttempts = 3
+
+// TxRetryOptions 事务重试配置，零值字段使用默认值
+type TxRetryOptions struct {
+	TxOptions   *sql.TxOptions              // 隔离级别 / 只读选项
+	MaxAttempts int                         // 最大执行次数 (含首次)，默认 3
+	Backoff     middleware.BackoffStrategy  // 重试等待策略，默认带抖动的指数退避
+	Retryable   middleware.RetryableChecker // 可重试错误检查，默认 middleware.TxRetryableChecker
+}
+
+// TransactionWithRetry 在事务中执行 fn，遇到死锁、锁等待超时、序列化失败或 SQLite BUSY 时回滚并重新执行整个 fn
+// fn 可能被执行多次，不应包含事务外的副作用；其他错误不重试，原样返回
+// 重试次数耗尽时返回 ErrorCodeDBDeadlock 错误，详情包含执行次数，最后一次错误作为 cause 保留
+// 已处于事务中时冲突会导致外层事务整体失效，此时只以保存点执行一次，由最外层事务负责重试
+func (b *Builder) TransactionWithRetry(opts *TxRetryOptions, fn func(*Builder) error) error {
+	var retry TxRetryOptions
+	if opts != nil {
+		retry = *opts
+	}
+	if b.txDepth > 0 {
+		return b.TransactionWithOptions(retry.TxOptions, fn)
+	}
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = defaultTxRetryAttempts
+	}
+	if retry.Backoff == nil {
+		retry.Backoff = middleware.NewJitterBackoff(middleware.NewDefaultBackoff(), 0.5)
+	}
+	if retry.Retryable == nil {
+		retry.Retryable = middleware.NewTxRetryableChecker()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := b.TransactionWithOptions(retry.TxOptions, fn)
+		if err == nil || !retry.Retryable.IsRetryable(err) {
+			return err
+		}
+		if attempt >= retry.MaxAttempts {
+			return errors.NewErrorf(errors.ErrorCodeDBDeadlock, errors.MsgTxRetryExhausted, attempt, err).WithCause(err)
+		}
+
+		timer := time.NewTimer(retry.Backoff.NextBackoff(attempt))
+		select {
+		case <-timer.C:
+		case <-b.ctx.Done():
+			timer.Stop()
+			return b.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 18:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 18:00:00
+ * @FilePath: \go-sqlbuilder\builder_tx_retry_test.go
+ * @Description: 事务死锁重试测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransactionWithRetry 测试死锁时重新执行整个事务，其他错误不重试
+func TestTransactionWithRetry(t *testing.T) {
+	db := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, db)
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	opts := &TxRetryOptions{Backoff: middleware.NewLinearBackoff(time.Millisecond)}
+
+	// 前两次死锁，每次的写入随回滚丢弃，第三次提交
+	attempts := 0
+	err := b.TransactionWithRetry(opts, func(tx *Builder) error {
+		attempts++
+		insertTxUser(t, tx, fmt.Sprintf("Retry%d", attempts))
+		if attempts < 3 {
+			return fmt.Errorf("update stock: %w", deadlock)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 0, countTxUsers(t, db, "Retry1", "Retry2"))
+	assert.Equal(t, 1, countTxUsers(t, db, "Retry3"))
+
+	// 重试耗尽
+	attempts = 0
+	err = b.TransactionWithRetry(opts, func(tx *Builder) error {
+		attempts++
+		return deadlock
+	})
+	assert.Equal(t, 3, attempts)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeDBDeadlock))
+	assert.Contains(t, err.Error(), "after 3 attempts")
+	assert.ErrorIs(t, err, deadlock)
+
+	// 其他错误不重试
+	attempts = 0
+	err = b.TransactionWithRetry(nil, func(tx *Builder) error {
+		attempts++
+		return errTxAbort
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	assert.Equal(t, 1, attempts)
+
+	// 嵌套调用只以保存点执行一次
+	attempts = 0
+	err = b.Transaction(func(tx *Builder) error {
+		return tx.TransactionWithRetry(opts, func(inner *Builder) error {
+			attempts++
+			return deadlock
+		})
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 1, attempts)
+
+	// 等待重试期间 context 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	err = b.WithContext(ctx).TransactionWithRetry(&TxRetryOptions{Backoff: middleware.NewLinearBackoff(time.Hour)}, func(tx *Builder) error {
+		cancel()
+		return deadlock
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	t.Logf("✓ 事务死锁重试测试通过")
+}
@@ -0,0 +1,176 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 14:00:00
+ * @FilePath: \go-sqlbuilder\builder_tx_test.go
+ * @Description: 事务与嵌套事务测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var errTxAbort = stderrors.New("abort")
+
+func insertTxUser(t *testing.T, b *Builder, name string) {
+	t.Helper()
+
+	_, err := b.Table("users").Insert(map[string]interface{}{"name": name, "status": "active"}).Exec()
+	require.NoError(t, err)
+}
+
+func countTxUsers(t *testing.T, db *sqlx.DB, names ...string) int {
+	t.Helper()
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM users WHERE name IN (?)", names)
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, db.Get(&count, query, args...))
+	return count
+}
+
+// TestTransaction_CommitAndRollback 测试提交、错误回滚与 panic 回滚
+func TestTransaction_CommitAndRollback(t *testing.T) {
+	db := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, db)
+
+	err := b.Transaction(func(tx *Builder) error {
+		assert.True(t, tx.InTransaction())
+		insertTxUser(t, tx, "Dave")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, b.InTransaction())
+	assert.Equal(t, 1, countTxUsers(t, db, "Dave"))
+
+	err = b.Transaction(func(tx *Builder) error {
+		insertTxUser(t, tx, "Eve")
+		return errTxAbort
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	assert.Equal(t, 0, countTxUsers(t, db, "Eve"))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = b.Transaction(func(tx *Builder) error {
+			insertTxUser(t, tx, "Frank")
+			panic("boom")
+		})
+	})
+	assert.Equal(t, 0, countTxUsers(t, db, "Frank"))
+
+	err = b.TransactionWithOptions(&sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *Builder) error {
+		insertTxUser(t, tx, "Grace")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countTxUsers(t, db, "Grace"))
+	t.Logf("✓ 事务提交与回滚测试通过")
+}
+
+// TestTransaction_Nested 测试基于保存点的嵌套事务
+func TestTransaction_Nested(t *testing.T) {
+	db := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, db)
+
+	err := b.Transaction(func(tx *Builder) error {
+		insertTxUser(t, tx, "Outer")
+
+		// 内层失败只回滚到保存点
+		err := tx.Transaction(func(inner *Builder) error {
+			insertTxUser(t, inner, "InnerFailed")
+			return errTxAbort
+		})
+		assert.ErrorIs(t, err, errTxAbort)
+
+		// 内层 panic 回滚到保存点后继续抛出
+		assert.Panics(t, func() {
+			_ = tx.Transaction(func(inner *Builder) error {
+				insertTxUser(t, inner, "InnerPanic")
+				panic("boom")
+			})
+		})
+
+		return tx.Transaction(func(inner *Builder) error {
+			insertTxUser(t, inner, "Inner")
+			return inner.Transaction(func(deepest *Builder) error {
+				insertTxUser(t, deepest, "Deepest")
+				return nil
+			})
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, countTxUsers(t, db, "Outer", "Inner", "Deepest"))
+	assert.Equal(t, 0, countTxUsers(t, db, "InnerFailed", "InnerPanic"))
+
+	// 外层回滚时已释放的保存点一并回滚
+	err = b.Transaction(func(tx *Builder) error {
+		require.NoError(t, tx.Transaction(func(inner *Builder) error {
+			insertTxUser(t, inner, "Discarded")
+			return nil
+		}))
+		return errTxAbort
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	assert.Equal(t, 0, countTxUsers(t, db, "Discarded"))
+	t.Logf("✓ 嵌套事务测试通过")
+}
+
+// TestTransaction_TxAdapters 测试事务适配器: 已在事务中的连接退化为保存点，GORM 事务可提交/回滚
+func TestTransaction_TxAdapters(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	sqlxTx, err := db.Beginx()
+	require.NoError(t, err)
+	txBuilder, err := New(sqlxTx)
+	require.NoError(t, err)
+	err = txBuilder.Transaction(func(inner *Builder) error {
+		insertTxUser(t, inner, "Savepoint")
+		return errTxAbort
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	insertTxUser(t, txBuilder, "Kept")
+	require.NoError(t, sqlxTx.Commit())
+	assert.Equal(t, 1, countTxUsers(t, db, "Kept"))
+	assert.Equal(t, 0, countTxUsers(t, db, "Savepoint"))
+
+	gormDB, err := gorm.Open(sqlite.Dialector{Conn: db.DB}, &gorm.Config{})
+	require.NoError(t, err)
+	gb, err := New(gormDB)
+	require.NoError(t, err)
+	err = gb.Transaction(func(tx *Builder) error {
+		insertTxUser(t, tx, "GormOuter")
+		_ = tx.Transaction(func(inner *Builder) error {
+			insertTxUser(t, inner, "GormInner")
+			return errTxAbort
+		})
+		count, err := tx.Table("users").Where("name", "=", "GormOuter").Count()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countTxUsers(t, db, "GormOuter"))
+	assert.Equal(t, 0, countTxUsers(t, db, "GormInner"))
+
+	gormTx := NewGormAdapter(gormDB)
+	tx, err := gormTx.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.(UniversalAdapterInterface).BeginTx(context.Background(), nil)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeNestedTransaction))
+	require.NoError(t, tx.Rollback())
+	t.Logf("✓ 事务适配器测试通过")
+}
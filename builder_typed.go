@@ -0,0 +1,292 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_typed.go
+ * @Description: 泛型类型安全查询 - 由结构体标签推导表名与列
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// Tabler 自定义表名 (与 GORM 约定一致)
+type Tabler interface {
+	TableName() string
+}
+
+// Query 泛型查询，列名按 db > gorm column > json > snake_case 解析
+// 主键为 gorm:"primaryKey" 标记的字段，未标记时为 id 列
+type Query[T any] struct {
+	builder *Builder
+	model   *typedModel
+}
+
+// typedModel 结构体映射信息
+type typedModel struct {
+	table      string
+	columns    []structColumn
+	primaryKey *structColumn
+}
+
+// NewQuery 基于构建器创建类型 T 的查询，不修改传入的构建器
+func NewQuery[T any](b *Builder) *Query[T] {
+	model := modelOf(reflect.TypeOf((*T)(nil)).Elem())
+
+	columns := make([]string, len(model.columns))
+	for i, col := range model.columns {
+		columns[i] = col.name
+	}
+
+	builder := b.Clone().Table(model.table).Select(columns...)
+	return &Query[T]{builder: builder, model: model}
+}
+
+// modelOf 解析表名、列与主键
+func modelOf(t reflect.Type) *typedModel {
+	model := &typedModel{columns: structColumns(t)}
+
+	if tabler, ok := reflect.New(t).Interface().(Tabler); ok {
+		model.table = tabler.TableName()
+	} else {
+		model.table = column.TableName(t.Name())
+	}
+
+	for i := range model.columns {
+		col := &model.columns[i]
+		if isPrimaryKeyField(col.field) {
+			model.primaryKey = col
+			break
+		}
+		if model.primaryKey == nil && col.name == constant.FieldID {
+			model.primaryKey = col
+		}
+	}
+	return model
+}
+
+// isPrimaryKeyField gorm:"primaryKey" / gorm:"primary_key"
+func isPrimaryKeyField(field reflect.StructField) bool {
+	for _, part := range strings.Split(field.Tag.Get("gorm"), ";") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "primarykey", "primary_key":
+			return true
+		}
+	}
+	return false
+}
+
+// ==================== 条件 ====================
+
+// with 沿用构建器的可变/不可变语义
+func (q *Query[T]) with(b *Builder) *Query[T] {
+	if b == q.builder {
+		return q
+	}
+	return &Query[T]{builder: b, model: q.model}
+}
+
+// Where 条件
+func (q *Query[T]) Where(column, operator string, value interface{}) *Query[T] {
+	return q.with(q.builder.Where(column, operator, value))
+}
+
+// OrWhere OR 条件
+func (q *Query[T]) OrWhere(column, operator string, value interface{}) *Query[T] {
+	return q.with(q.builder.OrWhere(column, operator, value))
+}
+
+// WhereIn IN 条件
+func (q *Query[T]) WhereIn(column string, values ...interface{}) *Query[T] {
+	return q.with(q.builder.WhereIn(column, values...))
+}
+
+// WhereRaw 原生条件
+func (q *Query[T]) WhereRaw(sql string, args ...interface{}) *Query[T] {
+	return q.with(q.builder.WhereRaw(sql, args...))
+}
+
+// OrderBy 升序
+func (q *Query[T]) OrderBy(column string) *Query[T] {
+	return q.with(q.builder.OrderBy(column))
+}
+
+// OrderByDesc 降序
+func (q *Query[T]) OrderByDesc(column string) *Query[T] {
+	return q.with(q.builder.OrderByDesc(column))
+}
+
+// Limit 限制数量
+func (q *Query[T]) Limit(limit int64) *Query[T] {
+	return q.with(q.builder.Limit(limit))
+}
+
+// Offset 偏移量
+func (q *Query[T]) Offset(offset int64) *Query[T] {
+	return q.with(q.builder.Offset(offset))
+}
+
+// Builder 底层构建器，用于类型查询未覆盖的子句
+func (q *Query[T]) Builder() *Builder {
+	return q.builder
+}
+
+// ToSQL 生成查询SQL
+func (q *Query[T]) ToSQL() (string, []interface{}) {
+	return q.builder.ToSQL()
+}
+
+// ==================== 读取 ====================
+
+// Find 查询全部匹配记录
+func (q *Query[T]) Find() ([]T, error) {
+	var records []T
+	if err := q.builder.Get(&records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// First 查询第一条记录，无记录时返回 sql.ErrNoRows
+func (q *Query[T]) First() (T, error) {
+	var record T
+	err := q.builder.First(&record)
+	return record, err
+}
+
+// Count 计数
+func (q *Query[T]) Count() (int64, error) {
+	return q.builder.Count()
+}
+
+// ==================== 写入 ====================
+
+// Insert 插入记录，主键为零值时由数据库生成并回写到 entity
+func (q *Query[T]) Insert(entity *T) error {
+	value := reflect.ValueOf(entity).Elem()
+	pk := q.model.primaryKey
+	autoKey := pk != nil && isZeroField(value, pk.index)
+
+	var cols []string
+	var values []interface{}
+	for _, col := range q.model.columns {
+		if autoKey && col.name == pk.name {
+			continue
+		}
+		field, ok := fieldValue(value, col.index)
+		if !ok {
+			continue
+		}
+		cols = append(cols, col.name)
+		values = append(values, field.Interface())
+	}
+
+	insert := q.writeBuilder().InsertColumns(cols, values)
+	if !autoKey {
+		_, err := insert.Exec()
+		return err
+	}
+
+	field, _ := fieldValue(value, pk.index)
+	if insert.supportsReturning() {
+		return insert.Returning(pk.name).ExecReturning(field.Addr().Interface())
+	}
+
+	result, err := insert.Exec()
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return setIntField(field, id)
+}
+
+// Update 按主键更新除主键外的全部列，返回影响行数
+func (q *Query[T]) Update(entity *T) (int64, error) {
+	pk := q.model.primaryKey
+	if pk == nil {
+		return 0, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgPrimaryKeyRequired)
+	}
+
+	value := reflect.ValueOf(entity).Elem()
+	update := q.writeBuilder()
+	for _, col := range q.model.columns {
+		if col.name == pk.name {
+			continue
+		}
+		if field, ok := fieldValue(value, col.index); ok {
+			update = update.Set(col.name, field.Interface())
+		}
+	}
+
+	key, ok := fieldValue(value, pk.index)
+	if !ok {
+		return 0, errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgPrimaryKeyUnreachable, pk.name)
+	}
+	result, err := update.Where(pk.name, "=", key.Interface()).Exec()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Delete 按当前条件删除，返回影响行数；未设置任何条件时拒绝执行，避免误删整表
+func (q *Query[T]) Delete() (int64, error) {
+	if len(q.builder.wheres) == 0 {
+		return 0, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgAtLeastOneFilterRequired)
+	}
+	result, err := q.builder.Clone().Delete().Exec()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// writeBuilder 写操作构建器: 沿用连接、方言与条件，去掉选择列与分页
+func (q *Query[T]) writeBuilder() *Builder {
+	b := q.builder.Clone()
+	b.immutable = false
+	b.columns = nil
+	b.orderByCols = nil
+	b.limitVal = 0
+	b.offsetVal = 0
+	return b
+}
+
+// fieldValue 按索引路径取字段，嵌入指针为 nil 时返回 false
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	return field, true
+}
+
+func isZeroField(v reflect.Value, index []int) bool {
+	field, ok := fieldValue(v, index)
+	return !ok || field.IsZero()
+}
+
+// setIntField 回写自增主键
+func setIntField(field reflect.Value, id int64) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	default:
+		return errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgUnexpectedType, field.Type().String(), "integer")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 10:00:00
+ * @FilePath: \go-sqlbuilder\builder_typed_test.go
+ * @Description: 泛型类型安全查询测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedUser struct {
+	ID     int64  `gorm:"primaryKey"`
+	Name   string `db:"name"`
+	Age    *int   `json:"age"`
+	Status string
+}
+
+func (typedUser) TableName() string { return "users" }
+
+type typedBase struct {
+	ID int64 `gorm:"primaryKey"`
+}
+
+type embeddedUser struct {
+	*typedBase
+	Name string
+}
+
+func (embeddedUser) TableName() string { return "users" }
+
+type AuditLog struct {
+	LogID  int64 `gorm:"column:log_id;primaryKey"`
+	Action string
+}
+
+type Category struct {
+	ID        int64
+	Name      string
+	Parent    *Category
+	Children  []Category
+	Posts     []AuditLog
+	Meta      map[string]string
+	Extra     map[string]string `gorm:"serializer:json"`
+	Icon      []byte
+	Deleted   sql.NullTime
+	CreatedAt time.Time
+}
+
+// TestQueryModel 测试表名、列与主键推导
+func TestQueryModel(t *testing.T) {
+	builder := (&Builder{ctx: context.Background()}).WithDialect("postgres")
+
+	sql, args := NewQuery[typedUser](builder).Where("status", "=", "active").OrderBy("id").ToSQL()
+	assert.Equal(t, `SELECT "id", "name", "age", "status" FROM "users" WHERE "status" = $1 ORDER BY "id" ASC`, sql)
+	assert.Equal(t, []interface{}{"active"}, args)
+
+	model := modelOf(reflect.TypeOf(AuditLog{}))
+	assert.Equal(t, "audit_logs", model.table)
+	require.NotNil(t, model.primaryKey)
+	assert.Equal(t, "log_id", model.primaryKey.name)
+
+	// 关联字段不映射为列，表名按 GORM 约定复数化
+	model = modelOf(reflect.TypeOf(Category{}))
+	assert.Equal(t, "categories", model.table)
+	names := make([]string, len(model.columns))
+	for i, col := range model.columns {
+		names[i] = col.name
+	}
+	assert.Equal(t, []string{"id", "name", "extra", "icon", "deleted", "created_at"}, names)
+
+	t.Logf("✓ 类型查询SQL: %s", sql)
+}
+
+// TestQueryCRUD 测试在SQLite上的类型安全增删改查
+func TestQueryCRUD(t *testing.T) {
+	db := newSQLiteDB(t)
+	users := func() *Query[typedUser] { return NewQuery[typedUser](newSQLiteBuilder(t, db)) }
+
+	active, err := users().Where("status", "=", "active").OrderBy("id").Find()
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, "Alice", active[0].Name)
+	assert.Nil(t, active[1].Age)
+
+	age := 41
+	dave := typedUser{Name: "Dave", Age: &age, Status: "active"}
+	require.NoError(t, users().Insert(&dave))
+	assert.Equal(t, int64(4), dave.ID)
+
+	dave.Status = "inactive"
+	affected, err := users().Update(&dave)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	found, err := users().Where("id", "=", dave.ID).First()
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", found.Status)
+	assert.Equal(t, 41, *found.Age)
+
+	deleted, err := users().Where("status", "=", "inactive").Delete()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	_, err = users().Where("id", "=", dave.ID).First()
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 无条件删除被拒绝
+	_, err = users().Delete()
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidInput))
+
+	count, err := users().Count()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// 主键位于 nil 嵌入指针中时返回错误而不是 panic
+	_, err = NewQuery[embeddedUser](newSQLiteBuilder(t, db)).Update(&embeddedUser{Name: "Ghost"})
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidInput))
+	assert.Contains(t, err.Error(), "nil embedded struct")
+
+	t.Logf("✓ 类型安全CRUD: inserted id=%d", dave.ID)
+}
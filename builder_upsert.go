@@ -0,0 +1,213 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 11:00:00
+ * @FilePath: \go-sqlbuilder\builder_upsert.go
+ * @Description: 原生 UPSERT 支持 - ON DUPLICATE KEY UPDATE / ON CONFLICT / MERGE
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+)
+
+// upsertDialect 驱动适配器的 UPSERT 方言，Builder.Upsert 与 DriverAdapterInterface.BuildUpsert 共用
+// 参数均为已引用的标识符，rows 为每行的占位符列表，assignments 为空时冲突忽略 (DO NOTHING)
+type upsertDialect interface {
+	excluded(column string) string
+	upsertStatement(table string, columns, rows, conflict, assignments []string) (string, error)
+}
+
+// upsertSet 冲突时的自定义更新表达式: column = expr
+type upsertSet struct {
+	column string
+	expr   string
+	args   []interface{}
+}
+
+// Upsert 插入，冲突时更新
+// conflictCols 为唯一键列 (MySQL 由唯一索引自动判定，可为空)
+// updateCols 为冲突时更新的列，为空时更新除冲突列外的全部列
+func (b *Builder) Upsert(data map[string]interface{}, conflictCols, updateCols []string) *Builder {
+	return b.UpsertBatch([]map[string]interface{}{data}, conflictCols, updateCols)
+}
+
+// UpsertBatch 多行插入，冲突时更新 (列以第一行为准，其余行缺失的列写入 NULL)
+func (b *Builder) UpsertBatch(rows []map[string]interface{}, conflictCols, updateCols []string) *Builder {
+	b = b.derive()
+	if !b.checkColumns(conflictCols...) || !b.checkColumns(updateCols...) {
+		return b
+	}
+	for _, row := range rows {
+		for col := range row {
+			if !b.checkColumns(col) {
+				return b
+			}
+		}
+	}
+	b.queryType = "upsert"
+	b.upsertRows = rows
+	b.upsertConflict = cloneStrings(conflictCols)
+	b.upsertUpdate = cloneStrings(updateCols)
+	b.upsertSets = nil
+	b.upsertNothing = false
+	return b
+}
+
+// OnConflictDoNothing 冲突时忽略 (DO NOTHING)
+func (b *Builder) OnConflictDoNothing() *Builder {
+	b = b.derive()
+	b.upsertNothing = true
+	return b
+}
+
+// OnConflictSet 冲突时使用自定义表达式更新列，可配合 Excluded 引用待插入的值
+// 如 OnConflictSet("stock", "stock + "+b.Excluded("stock"))
+func (b *Builder) OnConflictSet(column, expr string, args ...interface{}) *Builder {
+	b = b.derive()
+	if !b.checkColumns(column) {
+		return b
+	}
+	b.upsertSets = append(b.upsertSets, upsertSet{column: column, expr: expr, args: args})
+	return b
+}
+
+// Excluded 引用冲突行中待插入的值
+// MySQL: VALUES(col)，PostgreSQL/SQLite: EXCLUDED.col，SQL Server: source.col
+func (b *Builder) Excluded(column string) string {
+	return b.upsertDialect().excluded(b.quoteIdentifier(column))
+}
+
+// upsertDialect 当前方言的 UPSERT 实现，未知方言按 MySQL 处理
+func (b *Builder) upsertDialect() upsertDialect {
+	if dialect, ok := b.driver.(upsertDialect); ok {
+		return dialect
+	}
+	return &MySQLDriverAdapter{}
+}
+
+// upsertColumns 第一行的列 (按名称排序)
+func (b *Builder) upsertColumns() []string {
+	if len(b.upsertRows) == 0 {
+		return nil
+	}
+	return sortedKeys(b.upsertRows[0])
+}
+
+// upsertAssignments 冲突时的更新赋值列表及其参数
+func (b *Builder) upsertAssignments(columns []string) ([]string, []interface{}) {
+	if b.upsertNothing {
+		return nil, nil
+	}
+
+	updateCols := b.upsertUpdate
+	if len(updateCols) == 0 {
+		conflict := make(map[string]bool, len(b.upsertConflict))
+		for _, col := range b.upsertConflict {
+			conflict[col] = true
+		}
+		for _, col := range columns {
+			if !conflict[col] {
+				updateCols = append(updateCols, col)
+			}
+		}
+	}
+
+	custom := make(map[string]bool, len(b.upsertSets))
+	for _, set := range b.upsertSets {
+		custom[set.column] = true
+	}
+
+	assignments := make([]string, 0, len(updateCols)+len(b.upsertSets))
+	var args []interface{}
+	for _, col := range updateCols {
+		if custom[col] {
+			continue
+		}
+		assignments = append(assignments, fmt.Sprintf("%s = %s", b.quoteIdentifier(col), b.Excluded(col)))
+	}
+	for _, set := range b.upsertSets {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", b.quoteIdentifier(set.column), set.expr))
+		args = append(args, set.args...)
+	}
+	return assignments, args
+}
+
+// buildUpsert 按方言生成 UPSERT 语句，更新列为空时退化为 DO NOTHING
+// 语句无法生成时返回空语句，原因由 upsertErr 给出
+func (b *Builder) buildUpsert() (string, []interface{}) {
+	sql, args, err := b.upsertSQL()
+	if err != nil {
+		return "", nil
+	}
+	return sql, args
+}
+
+// upsertErr UPSERT 语句无法生成的原因: PostgreSQL/SQLite 的 DO UPDATE 与 SQL Server 的 MERGE 缺少冲突列
+// 冲突处理可在 Upsert 之后继续配置 (如 OnConflictDoNothing)，因此在执行时检查，由 Err() 和执行方法返回
+func (b *Builder) upsertErr() error {
+	if b.queryType != "upsert" {
+		return nil
+	}
+	_, _, err := b.upsertSQL()
+	return err
+}
+
+// upsertSQL 生成 UPSERT 语句及参数，不修改构建器状态
+func (b *Builder) upsertSQL() (string, []interface{}, error) {
+	columns := b.upsertColumns()
+	if len(columns) == 0 {
+		return "", nil, nil
+	}
+
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	values := make([]string, len(b.upsertRows))
+	args := make([]interface{}, 0, len(columns)*len(b.upsertRows))
+	for i, row := range b.upsertRows {
+		values[i] = rowPlaceholder
+		for _, col := range columns {
+			args = append(args, row[col])
+		}
+	}
+
+	assignments, setArgs := b.upsertAssignments(columns)
+	args = append(args, setArgs...)
+
+	sql, err := b.upsertDialect().upsertStatement(b.quoteIdentifier(b.table), b.quoteIdentifiers(columns), values,
+		b.quoteIdentifiers(b.upsertConflict), assignments)
+	if err != nil {
+		return "", nil, err
+	}
+	if b.GetDialect() == constant.DialectSQLServer {
+		return sql + b.buildOutput("INSERTED") + ";", args, nil
+	}
+	return sql + b.buildReturning(), args, nil
+}
+
+func cloneRows(src []map[string]interface{}) []map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+	dst := make([]map[string]interface{}, len(src))
+	for i, row := range src {
+		dst[i] = cloneMap(row)
+	}
+	return dst
+}
+
+func cloneUpsertSets(src []upsertSet) []upsertSet {
+	if src == nil {
+		return nil
+	}
+	dst := make([]upsertSet, len(src))
+	for i, set := range src {
+		dst[i] = upsertSet{column: set.column, expr: set.expr, args: cloneArgs(set.args)}
+	}
+	return dst
+}
@@ -0,0 +1,152 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 11:00:00
+ * @FilePath: \go-sqlbuilder\builder_upsert_test.go
+ * @Description: UPSERT 测试 - 各方言SQL渲染与SQLite执行
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderUpsertDialects 测试各方言的 UPSERT 语法
+func TestBuilderUpsertDialects(t *testing.T) {
+	data := map[string]interface{}{"email": "a@example.com", "name": "Alice", "age": 30}
+
+	cases := []struct {
+		dialect string
+		want    string
+	}{
+		{"mysql", "INSERT INTO `users` (`age`, `email`, `name`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`), `name` = VALUES(`name`)"},
+		{"postgres", `INSERT INTO "users" ("age", "email", "name") VALUES ($1, $2, $3) ON CONFLICT ("email") DO UPDATE SET "age" = EXCLUDED."age", "name" = EXCLUDED."name"`},
+		{"sqlite", `INSERT INTO "users" ("age", "email", "name") VALUES (?, ?, ?) ON CONFLICT ("email") DO UPDATE SET "age" = EXCLUDED."age", "name" = EXCLUDED."name"`},
+		{"sqlserver", "MERGE INTO [users] AS target USING (VALUES (@p1, @p2, @p3)) AS source ([age], [email], [name]) ON target.[email] = source.[email] WHEN MATCHED THEN UPDATE SET target.[age] = source.[age], target.[name] = source.[name] WHEN NOT MATCHED THEN INSERT ([age], [email], [name]) VALUES (source.[age], source.[email], source.[name]);"},
+	}
+
+	for _, tc := range cases {
+		builder := &Builder{ctx: context.Background()}
+		sql, args := builder.WithDialect(tc.dialect).Table("users").Upsert(data, []string{"email"}, nil).ToSQL()
+		assert.Equal(t, tc.want, sql, tc.dialect)
+		assert.Equal(t, []interface{}{30, "a@example.com", "Alice"}, args, tc.dialect)
+	}
+
+	t.Logf("✓ UPSERT 方言渲染正确")
+}
+
+// TestBuilderUpsertBatchAndCustomSet 测试批量 UPSERT、指定更新列与自定义表达式
+func TestBuilderUpsertBatchAndCustomSet(t *testing.T) {
+	builder := &Builder{ctx: context.Background()}
+
+	rows := []map[string]interface{}{
+		{"sku": "A1", "stock": 5, "name": "apple"},
+		{"sku": "B2", "stock": 3, "name": "banana"},
+	}
+
+	sql, args := builder.
+		WithDialect("postgres").
+		Table("products").
+		UpsertBatch(rows, []string{"sku"}, []string{"name"}).
+		OnConflictSet("stock", `"products"."stock" + EXCLUDED."stock" + ?`, 1).
+		ToSQL()
+
+	assert.Equal(t, `INSERT INTO "products" ("name", "sku", "stock") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("sku") DO UPDATE SET "name" = EXCLUDED."name", "stock" = "products"."stock" + EXCLUDED."stock" + $7`, sql)
+	assert.Equal(t, []interface{}{"apple", "A1", 5, "banana", "B2", 3, 1}, args)
+
+	t.Logf("✓ 批量 UPSERT SQL: %s", sql)
+}
+
+// TestBuilderUpsertDoNothing 测试 DO NOTHING 及 MySQL 自赋值退化
+func TestBuilderUpsertDoNothing(t *testing.T) {
+	data := map[string]interface{}{"id": 1, "name": "Alice"}
+
+	pgSQL, _ := (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("users").Upsert(data, []string{"id"}, nil).OnConflictDoNothing().ToSQL()
+	assert.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO NOTHING`, pgSQL)
+
+	mysqlSQL, _ := (&Builder{ctx: context.Background()}).WithDialect("mysql").
+		Table("users").Upsert(data, []string{"id"}, nil).OnConflictDoNothing().ToSQL()
+	assert.Equal(t, "INSERT INTO `users` (`id`, `name`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `id` = `id`", mysqlSQL)
+
+	msSQL, _ := (&Builder{ctx: context.Background()}).WithDialect("sqlserver").
+		Table("users").Upsert(data, []string{"id"}, nil).OnConflictDoNothing().ToSQL()
+	assert.NotContains(t, msSQL, "WHEN MATCHED")
+
+	// 未指定冲突列: PostgreSQL 允许 DO NOTHING
+	pgSQL, _ = (&Builder{ctx: context.Background()}).WithDialect("postgres").
+		Table("users").Upsert(data, nil, nil).OnConflictDoNothing().ToSQL()
+	assert.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT DO NOTHING`, pgSQL)
+
+	t.Logf("✓ DO NOTHING 渲染正确")
+}
+
+// TestBuilderUpsertConflictRequired 测试 DO UPDATE / MERGE 缺少冲突列时报错，且与适配器 BuildUpsert 一致
+func TestBuilderUpsertConflictRequired(t *testing.T) {
+	data := map[string]interface{}{"id": 1, "name": "Alice"}
+
+	for _, dialect := range []string{"postgres", "sqlite", "sqlserver"} {
+		b := (&Builder{ctx: context.Background()}).WithDialect(dialect).Table("users").Upsert(data, nil, nil)
+		assert.True(t, errors.IsErrorCode(b.Err(), errors.ErrorCodeInvalidInput), dialect)
+		sql, _ := b.ToSQL()
+		assert.Empty(t, sql, dialect)
+		// ToSQL 不记录错误，错误只由 Err() 与执行方法给出
+		assert.Nil(t, b.err, dialect)
+	}
+
+	// 冲突处理在 Upsert 之后配置为 DO NOTHING 时合法
+	nothing := (&Builder{ctx: context.Background()}).WithDialect("postgres").Table("users").Upsert(data, nil, nil).OnConflictDoNothing()
+	assert.NoError(t, nothing.Err())
+	sql, _ := nothing.ToSQL()
+	assert.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT DO NOTHING`, sql)
+
+	db := newSQLiteDB(t)
+	_, err := newSQLiteBuilder(t, db).Table("users").Upsert(data, nil, nil).Exec()
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeInvalidInput))
+
+	// 适配器 BuildUpsert 复用同一实现
+	pgSQL, args := NewPostgreSQLDriverAdapter().BuildUpsert(`"users"`, data, []string{"id"})
+	assert.Equal(t, `INSERT INTO "users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, pgSQL)
+	assert.Equal(t, []interface{}{1, "Alice"}, args)
+	msSQL, _ := NewSQLServerDriverAdapter().BuildUpsert("[users]", data, []string{"id"})
+	assert.Equal(t, "MERGE INTO [users] AS target USING (VALUES (@p1, @p2)) AS source ([id], [name]) ON target.[id] = source.[id] WHEN MATCHED THEN UPDATE SET target.[name] = source.[name] WHEN NOT MATCHED THEN INSERT ([id], [name]) VALUES (source.[id], source.[name]);", msSQL)
+	pgSQL, _ = NewPostgreSQLDriverAdapter().BuildUpsert("users", data, nil)
+	assert.Empty(t, pgSQL)
+	var validator UpsertValidator = NewPostgreSQLDriverAdapter()
+	assert.True(t, errors.IsErrorCode(validator.ValidateUpsert("users", data, nil), errors.ErrorCodeInvalidInput))
+	assert.NoError(t, validator.ValidateUpsert("users", data, []string{"id"}))
+	assert.NoError(t, NewMySQLDriverAdapter().ValidateUpsert("users", data, nil))
+	t.Logf("✓ 缺少冲突列的 UPSERT 被拒绝")
+}
+
+// TestBuilderUpsertSQLite 测试在SQLite上执行 UPSERT
+func TestBuilderUpsertSQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	_, err := newSQLiteBuilder(t, db).Table("users").UpsertBatch([]map[string]interface{}{
+		{"id": 1, "name": "Alicia", "status": "active"},
+		{"id": 4, "name": "Dave", "status": "active"},
+	}, []string{"id"}, []string{"name"}).Exec()
+	require.NoError(t, err)
+
+	_, err = newSQLiteBuilder(t, db).Table("users").
+		Upsert(map[string]interface{}{"id": 2, "name": "Robert"}, []string{"id"}, nil).
+		OnConflictDoNothing().
+		Exec()
+	require.NoError(t, err)
+
+	var names []string
+	require.NoError(t, newSQLiteBuilder(t, db).Table("users").Select("name").OrderBy("id").Get(&names))
+	assert.Equal(t, []string{"Alicia", "Bob", "Carol", "Dave"}, names)
+
+	t.Logf("✓ SQLite UPSERT 执行结果: %v", names)
+}
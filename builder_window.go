@@ -0,0 +1,338 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 09:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 09:00:00
+ * @FilePath: \go-sqlbuilder\builder_window.go
+ * @Description: 窗口函数与命名窗口 (WINDOW) 支持
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// windowFramePattern 安全模式下允许的窗口帧: ROWS/RANGE/GROUPS 后仅含关键字与数字
+var windowFramePattern = regexp.MustCompile(`(?i)^(ROWS|RANGE|GROUPS)(\s+[A-Z0-9]+)+$`)
+
+// WindowSpec 窗口定义: PARTITION BY / ORDER BY / 帧
+type WindowSpec struct {
+	partitionBy []string
+	orderBy     []string
+	frame       string
+}
+
+// NewWindow 创建窗口定义，用于 Builder.Window 命名窗口
+func NewWindow() *WindowSpec {
+	return &WindowSpec{}
+}
+
+// PartitionBy 分区列
+func (w *WindowSpec) PartitionBy(columns ...string) *WindowSpec {
+	w.partitionBy = append(w.partitionBy, columns...)
+	return w
+}
+
+// OrderBy 窗口内升序
+func (w *WindowSpec) OrderBy(column string) *WindowSpec {
+	w.orderBy = append(w.orderBy, column+" ASC")
+	return w
+}
+
+// OrderByDesc 窗口内降序
+func (w *WindowSpec) OrderByDesc(column string) *WindowSpec {
+	w.orderBy = append(w.orderBy, column+" DESC")
+	return w
+}
+
+// Frame 窗口帧，如 "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"
+func (w *WindowSpec) Frame(frame string) *WindowSpec {
+	w.frame = frame
+	return w
+}
+
+func (w *WindowSpec) clone() *WindowSpec {
+	if w == nil {
+		return nil
+	}
+	return &WindowSpec{
+		partitionBy: cloneStrings(w.partitionBy),
+		orderBy:     cloneStrings(w.orderBy),
+		frame:       w.frame,
+	}
+}
+
+// WindowExpr 窗口函数表达式: FUNC(args) OVER (...) AS alias
+type WindowExpr struct {
+	function string
+	columns  []string
+	params   []interface{}
+	spec     WindowSpec
+	window   string // 引用的命名窗口
+	alias    string
+}
+
+// RowNumber ROW_NUMBER()
+func RowNumber() *WindowExpr {
+	return &WindowExpr{function: "ROW_NUMBER"}
+}
+
+// Rank RANK()
+func Rank() *WindowExpr {
+	return &WindowExpr{function: "RANK"}
+}
+
+// DenseRank DENSE_RANK()
+func DenseRank() *WindowExpr {
+	return &WindowExpr{function: "DENSE_RANK"}
+}
+
+// Lag LAG(column, offset[, default])
+func Lag(column string, offset int, defaultValue ...interface{}) *WindowExpr {
+	return offsetWindow("LAG", column, offset, defaultValue)
+}
+
+// Lead LEAD(column, offset[, default])
+func Lead(column string, offset int, defaultValue ...interface{}) *WindowExpr {
+	return offsetWindow("LEAD", column, offset, defaultValue)
+}
+
+// SumOver SUM(column) OVER (...)
+func SumOver(column string) *WindowExpr {
+	return &WindowExpr{function: "SUM", columns: []string{column}}
+}
+
+// AvgOver AVG(column) OVER (...)
+func AvgOver(column string) *WindowExpr {
+	return &WindowExpr{function: "AVG", columns: []string{column}}
+}
+
+// CountOver COUNT(column) OVER (...)，column 为空时使用 *
+func CountOver(column string) *WindowExpr {
+	if column == "" {
+		column = "*"
+	}
+	return &WindowExpr{function: "COUNT", columns: []string{column}}
+}
+
+// MinOver MIN(column) OVER (...)
+func MinOver(column string) *WindowExpr {
+	return &WindowExpr{function: "MIN", columns: []string{column}}
+}
+
+// MaxOver MAX(column) OVER (...)
+func MaxOver(column string) *WindowExpr {
+	return &WindowExpr{function: "MAX", columns: []string{column}}
+}
+
+func offsetWindow(function, column string, offset int, defaultValue []interface{}) *WindowExpr {
+	if offset <= 0 {
+		offset = 1
+	}
+	expr := &WindowExpr{function: function, columns: []string{column}, params: []interface{}{offset}}
+	if len(defaultValue) > 0 {
+		expr.params = append(expr.params, defaultValue[0])
+	}
+	return expr
+}
+
+// PartitionBy 分区列
+func (e *WindowExpr) PartitionBy(columns ...string) *WindowExpr {
+	e.spec.PartitionBy(columns...)
+	return e
+}
+
+// OrderBy 窗口内升序
+func (e *WindowExpr) OrderBy(column string) *WindowExpr {
+	e.spec.OrderBy(column)
+	return e
+}
+
+// OrderByDesc 窗口内降序
+func (e *WindowExpr) OrderByDesc(column string) *WindowExpr {
+	e.spec.OrderByDesc(column)
+	return e
+}
+
+// Frame 窗口帧
+func (e *WindowExpr) Frame(frame string) *WindowExpr {
+	e.spec.Frame(frame)
+	return e
+}
+
+// Over 使用 Builder.Window 定义的命名窗口
+func (e *WindowExpr) Over(window string) *WindowExpr {
+	e.window = window
+	return e
+}
+
+// As 结果列别名
+func (e *WindowExpr) As(alias string) *WindowExpr {
+	e.alias = alias
+	return e
+}
+
+func (e *WindowExpr) clone() *WindowExpr {
+	spec := e.spec.clone()
+	return &WindowExpr{
+		function: e.function,
+		columns:  cloneStrings(e.columns),
+		params:   cloneArgs(e.params),
+		spec:     *spec,
+		window:   e.window,
+		alias:    e.alias,
+	}
+}
+
+// namedWindow WINDOW name AS (...)
+type namedWindow struct {
+	name string
+	spec *WindowSpec
+}
+
+// ==================== Builder 集成 ====================
+
+// SelectWindow 在选择列之后追加窗口函数列
+func (b *Builder) SelectWindow(exprs ...*WindowExpr) *Builder {
+	b = b.derive()
+	for _, expr := range exprs {
+		if !b.checkColumns(expr.columns...) || !b.checkWindowSpec(&expr.spec) {
+			return b
+		}
+		if expr.window != "" && !b.checkTables(expr.window) {
+			return b
+		}
+		if expr.alias != "" && !b.checkTables(expr.alias) {
+			return b
+		}
+	}
+	b.queryType = "select"
+	for _, expr := range exprs {
+		b.windowExprs = append(b.windowExprs, expr.clone())
+	}
+	return b
+}
+
+// Window 定义命名窗口: WINDOW name AS (...)
+// SQL Server 不支持 WINDOW 子句，引用处会内联窗口定义
+func (b *Builder) Window(name string, spec *WindowSpec) *Builder {
+	b = b.derive()
+	if !b.checkTables(name) || !b.checkWindowSpec(spec) {
+		return b
+	}
+	b.namedWindows = append(b.namedWindows, namedWindow{name: name, spec: spec.clone()})
+	return b
+}
+
+// checkWindowSpec 安全模式下校验窗口的分区列、排序列与帧定义
+func (b *Builder) checkWindowSpec(spec *WindowSpec) bool {
+	if !b.checkColumns(spec.partitionBy...) {
+		return false
+	}
+	for _, order := range spec.orderBy {
+		if !b.checkColumns(order[:strings.LastIndex(order, " ")]) {
+			return false
+		}
+	}
+	if b.safeMode && spec.frame != "" && !windowFramePattern.MatchString(spec.frame) {
+		return b.reject(errors.NewErrorf(errors.ErrorCodeInvalidSQLQuery, errors.MsgInvalidWindowFrame, spec.frame))
+	}
+	return true
+}
+
+// inlineWindows 当前方言是否需要内联命名窗口
+func (b *Builder) inlineWindows() bool {
+	return b.GetDialect() == constant.DialectSQLServer
+}
+
+// buildWindowExprs 生成窗口函数列及其参数
+func (b *Builder) buildWindowExprs() ([]string, []interface{}) {
+	exprs := make([]string, 0, len(b.windowExprs))
+	var args []interface{}
+
+	for _, e := range b.windowExprs {
+		callArgs := b.quoteIdentifiers(e.columns)
+		for _, param := range e.params {
+			callArgs = append(callArgs, "?")
+			args = append(args, param)
+		}
+
+		over := b.buildWindowSpec(&e.spec)
+		if e.window != "" {
+			over = b.quoteIdentifier(e.window)
+			if b.inlineWindows() {
+				for _, w := range b.namedWindows {
+					if w.name == e.window {
+						over = b.buildWindowSpec(w.spec)
+						break
+					}
+				}
+			}
+		}
+
+		expr := fmt.Sprintf("%s(%s) OVER %s", e.function, strings.Join(callArgs, ", "), over)
+		if e.alias != "" {
+			expr += " AS " + b.quoteIdentifier(e.alias)
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return exprs, args
+}
+
+// buildWindowSpec 生成 (PARTITION BY ... ORDER BY ... 帧)
+func (b *Builder) buildWindowSpec(spec *WindowSpec) string {
+	parts := make([]string, 0, 3)
+	if len(spec.partitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(b.quoteIdentifiers(spec.partitionBy), ", "))
+	}
+	if len(spec.orderBy) > 0 {
+		orders := make([]string, len(spec.orderBy))
+		for i, order := range spec.orderBy {
+			idx := strings.LastIndex(order, " ")
+			orders[i] = b.quoteIdentifier(order[:idx]) + order[idx:]
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(orders, ", "))
+	}
+	if spec.frame != "" {
+		parts = append(parts, spec.frame)
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// buildWindowClause 生成 WINDOW 子句 (含前导空格)
+func (b *Builder) buildWindowClause() string {
+	if len(b.namedWindows) == 0 || b.inlineWindows() {
+		return ""
+	}
+	parts := make([]string, len(b.namedWindows))
+	for i, w := range b.namedWindows {
+		parts[i] = fmt.Sprintf("%s AS %s", b.quoteIdentifier(w.name), b.buildWindowSpec(w.spec))
+	}
+	return " WINDOW " + strings.Join(parts, ", ")
+}
+
+func cloneWindowExprs(src []*WindowExpr) []*WindowExpr {
+	if src == nil {
+		return nil
+	}
+	dst := make([]*WindowExpr, len(src))
+	copy(dst, src)
+	return dst
+}
+
+func cloneNamedWindows(src []namedWindow) []namedWindow {
+	if src == nil {
+		return nil
+	}
+	dst := make([]namedWindow, len(src))
+	copy(dst, src)
+	return dst
+}
@@ -0,0 +1,82 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 09:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 09:00:00
+ * @FilePath: \go-sqlbuilder\builder_window_test.go
+ * @Description: 窗口函数测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package sqlbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuilderWindowFunctions 测试窗口函数列渲染
+func TestBuilderWindowFunctions(t *testing.T) {
+	sql, args := (&Builder{ctx: context.Background()}).
+		WithDialect("mysql").
+		Table("scores").
+		Select("user_id", "score").
+		SelectWindow(
+			RowNumber().PartitionBy("game_id").OrderByDesc("score").As("rn"),
+			Lag("score", 1, 0).OrderBy("created_at").As("prev_score"),
+			SumOver("score").PartitionBy("user_id").Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW").As("running_total"),
+		).
+		Where("season", "=", 3).
+		ToSQL()
+
+	assert.Equal(t, "SELECT `user_id`, `score`, ROW_NUMBER() OVER (PARTITION BY `game_id` ORDER BY `score` DESC) AS `rn`, "+
+		"LAG(`score`, ?, ?) OVER (ORDER BY `created_at` ASC) AS `prev_score`, "+
+		"SUM(`score`) OVER (PARTITION BY `user_id` ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS `running_total` "+
+		"FROM `scores` WHERE `season` = ?", sql)
+	assert.Equal(t, []interface{}{1, 0, 3}, args)
+
+	t.Logf("✓ Window SQL: %s", sql)
+}
+
+// TestBuilderNamedWindow 测试命名窗口及SQL Server内联
+func TestBuilderNamedWindow(t *testing.T) {
+	build := func(dialect string) string {
+		sql, _ := (&Builder{ctx: context.Background()}).
+			WithDialect(dialect).
+			Table("scores").
+			Select("user_id").
+			SelectWindow(Rank().Over("w").As("rk"), AvgOver("score").Over("w").As("avg_score")).
+			Window("w", NewWindow().PartitionBy("game_id").OrderByDesc("score")).
+			ToSQL()
+		return sql
+	}
+
+	assert.Equal(t, `SELECT "user_id", RANK() OVER "w" AS "rk", AVG("score") OVER "w" AS "avg_score" FROM "scores" WINDOW "w" AS (PARTITION BY "game_id" ORDER BY "score" DESC)`, build("postgres"))
+	assert.Equal(t, "SELECT [user_id], RANK() OVER (PARTITION BY [game_id] ORDER BY [score] DESC) AS [rk], AVG([score]) OVER (PARTITION BY [game_id] ORDER BY [score] DESC) AS [avg_score] FROM [scores]", build("sqlserver"))
+}
+
+// TestBuilderWindowExecute 测试窗口函数在SQLite上执行
+func TestBuilderWindowExecute(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	var rows []struct {
+		Name string `db:"name"`
+		Rn   int    `db:"rn"`
+	}
+	err := newSQLiteBuilder(t, db).
+		Table("users").
+		Select("name").
+		SelectWindow(RowNumber().Over("w").As("rn")).
+		Window("w", NewWindow().PartitionBy("status").OrderBy("id")).
+		OrderBy("id").
+		Get(&rows)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, 1, rows[0].Rn)
+	assert.Equal(t, 1, rows[1].Rn)
+	assert.Equal(t, 2, rows[2].Rn)
+}
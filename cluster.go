@@ -0,0 +1,462 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 22:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 22:00:00
+ * @FilePath: \go-sqlbuilder\cluster.go
+ * @Description: 读写分离集群适配器 - 一主多从，只读查询路由到从库
+ *
+ * 用法:
+ *
+ *	cluster, _ := sqlbuilder.NewCluster(primaryDB, replicaDB1, replicaDB2)
+ *	cluster.WithStrategy(sqlbuilder.ReplicaLeastLatency).WithReadYourWrites(2 * time.Second)
+ *	builder, _ := sqlbuilder.New(cluster)
+ *	builder.Table("users").Get(&users)                                            // 从库
+ *	builder.WithContext(sqlbuilder.ForcePrimary(ctx)).Table("users").Get(&users)  // 主库
+ *	session := builder.WithContext(sqlbuilder.WithWriteSession(ctx))              // 会话内写入后窗口期读主库
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// ReplicaStrategy 从库选择策略
+type ReplicaStrategy string
+
+const (
+	ReplicaRoundRobin   ReplicaStrategy = "round_robin"   // 轮询
+	ReplicaRandom       ReplicaStrategy = "random"        // 随机
+	ReplicaLeastLatency ReplicaStrategy = "least_latency" // 最低平均延迟
+)
+
+// latencyDecay 延迟滑动平均中新样本的权重
+const latencyDecay = 0.2
+
+// replicaCooldown 从库查询失败后暂停使用的时长，期间读请求路由到其他从库或主库
+const replicaCooldown = 5 * time.Second
+
+// writeKeywordPattern 含写入或加锁语义的关键字，出现时语句路由到主库
+var writeKeywordPattern = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|REPLACE|INTO|LOCK|UPDLOCK|HOLDLOCK|SHARE)\b`)
+
+// forcePrimaryKey context 中强制主库标记的键
+type forcePrimaryKey struct{}
+
+// ForcePrimary 返回强制读主库的 context，用于刚写入后必须读到最新数据的场景
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+// isForcePrimary context 是否要求读主库
+func isForcePrimary(ctx context.Context) bool {
+	force, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return force
+}
+
+// writeSessionKey context 中读写会话的键
+type writeSessionKey struct{}
+
+// writeSession 读写会话，记录会话内最近一次写入时间
+type writeSession struct {
+	lastWrite atomic.Int64
+}
+
+// WithWriteSession 返回携带读写会话的 context，配合 Cluster.WithReadYourWrites 使用
+// 会话内写入 (含事务提交) 后窗口期内，同一会话的读请求路由到主库，其他会话不受影响
+func WithWriteSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeSessionKey{}, &writeSession{})
+}
+
+// sessionOf 获取 context 中的读写会话
+func sessionOf(ctx context.Context) *writeSession {
+	session, _ := ctx.Value(writeSessionKey{}).(*writeSession)
+	return session
+}
+
+// clusterReplica 从库及其延迟统计
+type clusterReplica struct {
+	adapter     UniversalAdapterInterface
+	latency     atomic.Int64 // 查询耗时滑动平均 (纳秒)，0 表示尚无样本
+	failedUntil atomic.Int64 // 查询失败后暂停使用的截止时间 (纳秒)
+}
+
+// available 从库当前是否可用
+func (r *clusterReplica) available(now time.Time) bool {
+	return now.UnixNano() >= r.failedUntil.Load()
+}
+
+// observe 记录一次查询结果: 成功时计入耗时，失败时暂停使用该从库
+// 失败的耗时不计入，避免快速失败的从库在最低延迟策略中胜出；调用方取消或超时不算从库故障
+func (r *clusterReplica) observe(d time.Duration, err error) {
+	if err != nil {
+		if !stderrors.Is(err, context.Canceled) && !stderrors.Is(err, context.DeadlineExceeded) {
+			r.failedUntil.Store(time.Now().Add(replicaCooldown).UnixNano())
+		}
+		return
+	}
+	old := r.latency.Load()
+	if old == 0 {
+		r.latency.Store(int64(d))
+		return
+	}
+	r.latency.Store(int64(float64(old)*(1-latencyDecay) + float64(d)*latencyDecay))
+}
+
+// restore 恢复从库，用于主库同样失败 (语句本身有误) 的情况
+func (r *clusterReplica) restore() {
+	r.failedUntil.Store(0)
+}
+
+// Cluster 读写分离集群适配器 - 实现通用适配器接口
+// 事务外的只读 SELECT 路由到从库，写入、加锁读与事务内语句路由到主库
+// 从库须与主库为同一方言、同一类型的实例，SQL 按主库方言生成
+// 从库查询失败时在主库重试，主库成功则该从库暂停使用一段时间；所有从库不可用时读主库
+type Cluster struct {
+	primary  UniversalAdapterInterface
+	replicas []*clusterReplica
+	strategy ReplicaStrategy
+	counter  atomic.Uint64
+
+	// 读己之写: 读写会话内最近一次写入后的窗口期内读主库，规避主从复制延迟
+	readYourWrites time.Duration
+
+	name string
+}
+
+// NewCluster 创建读写分离集群，primary 与 replicas 可以是 *sqlx.DB、*gorm.DB 或 UniversalAdapterInterface
+// 从库的适配器类型或方言与主库不一致时返回 ErrorCodeAdapterNotSupported 错误；未配置从库时所有语句都在主库执行
+func NewCluster(primary interface{}, replicas ...interface{}) (*Cluster, error) {
+	if primary == nil {
+		return nil, errors.NewError(errors.ErrorCodeNoDatabaseConn, errors.MsgNoDatabaseConnection)
+	}
+	primaryAdapter, err := NewUniversalAdapter(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cluster{
+		primary:  primaryAdapter,
+		replicas: make([]*clusterReplica, 0, len(replicas)),
+		strategy: ReplicaRoundRobin,
+		name:     "Cluster-Adapter",
+	}
+	for i, replica := range replicas {
+		adapter, err := NewUniversalAdapter(replica)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(adapter.GetAdapterType(), primaryAdapter.GetAdapterType()) || adapter.GetDialect() != primaryAdapter.GetDialect() {
+			return nil, errors.NewErrorf(errors.ErrorCodeAdapterNotSupported, errors.MsgReplicaMismatch, i,
+				adapter.GetAdapterType(), adapter.GetDialect(), primaryAdapter.GetAdapterType(), primaryAdapter.GetDialect())
+		}
+		c.replicas = append(c.replicas, &clusterReplica{adapter: adapter})
+	}
+	return c, nil
+}
+
+// WithStrategy 设置从库选择策略，默认轮询
+func (c *Cluster) WithStrategy(strategy ReplicaStrategy) *Cluster {
+	c.strategy = strategy
+	return c
+}
+
+// WithReadYourWrites 设置读己之写窗口，0 表示关闭
+// 仅对 WithWriteSession 标记的 context 生效: 会话内写入后 window 内，同一会话的读请求路由到主库
+func (c *Cluster) WithReadYourWrites(window time.Duration) *Cluster {
+	c.readYourWrites = window
+	return c
+}
+
+// Primary 获取主库适配器
+func (c *Cluster) Primary() UniversalAdapterInterface {
+	return c.primary
+}
+
+// Replicas 获取从库适配器
+func (c *Cluster) Replicas() []UniversalAdapterInterface {
+	replicas := make([]UniversalAdapterInterface, len(c.replicas))
+	for i, replica := range c.replicas {
+		replicas[i] = replica.adapter
+	}
+	return replicas
+}
+
+// ==================== 路由 ====================
+
+// readReplica 为只读语句选择可用从库，应读主库或没有可用从库时返回 nil
+func (c *Cluster) readReplica(ctx context.Context, query string) *clusterReplica {
+	if len(c.replicas) == 0 || isForcePrimary(ctx) || !isReadQuery(query) {
+		return nil
+	}
+	if c.readYourWrites > 0 {
+		if session := sessionOf(ctx); session != nil {
+			if last := session.lastWrite.Load(); last > 0 && time.Since(time.Unix(0, last)) < c.readYourWrites {
+				return nil
+			}
+		}
+	}
+
+	now := time.Now()
+	replicas := make([]*clusterReplica, 0, len(c.replicas))
+	for _, replica := range c.replicas {
+		if replica.available(now) {
+			replicas = append(replicas, replica)
+		}
+	}
+	if len(replicas) == 0 {
+		return nil
+	}
+
+	switch c.strategy {
+	case ReplicaRandom:
+		return replicas[rand.IntN(len(replicas))]
+	case ReplicaLeastLatency:
+		// 尚无样本的从库优先，使每个从库都被探测到
+		best := replicas[0]
+		for _, replica := range replicas[1:] {
+			if replica.latency.Load() < best.latency.Load() {
+				best = replica
+			}
+		}
+		return best
+	default:
+		n := c.counter.Add(1) - 1
+		return replicas[n%uint64(len(replicas))]
+	}
+}
+
+// markWrite 记录 ctx 所属读写会话的写入时间，开启该会话的读己之写窗口
+func (c *Cluster) markWrite(ctx context.Context) {
+	if c.readYourWrites <= 0 {
+		return
+	}
+	if session := sessionOf(ctx); session != nil {
+		session.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+// isReadQuery 是否为可在从库执行的只读查询: 以 SELECT / WITH 开头且不含写入或加锁关键字
+func isReadQuery(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	if len(query) < 4 {
+		return false
+	}
+	head := strings.ToUpper(query[:min(len(query), 6)])
+	if !strings.HasPrefix(head, "SELECT") && !strings.HasPrefix(head, "WITH") {
+		return false
+	}
+	return !writeKeywordPattern.MatchString(query)
+}
+
+// ==================== 通用适配器接口实现 ====================
+
+// GetAdapterType 获取适配器类型，与主库一致
+func (c *Cluster) GetAdapterType() string {
+	return c.primary.GetAdapterType()
+}
+
+// GetAdapterName 获取适配器名称
+func (c *Cluster) GetAdapterName() string {
+	return c.name
+}
+
+// GetDialect 获取数据库方言，与主库一致
+func (c *Cluster) GetDialect() string {
+	return c.primary.GetDialect()
+}
+
+// SupportsORM 是否支持ORM
+func (c *Cluster) SupportsORM() bool {
+	return c.primary.SupportsORM()
+}
+
+// SupportsUpsert 是否支持UPSERT
+func (c *Cluster) SupportsUpsert() bool {
+	return c.primary.SupportsUpsert()
+}
+
+// SupportsBulkInsert 是否支持批量插入
+func (c *Cluster) SupportsBulkInsert() bool {
+	return c.primary.SupportsBulkInsert()
+}
+
+// SupportsReturning 是否支持RETURNING
+func (c *Cluster) SupportsReturning() bool {
+	return c.primary.SupportsReturning()
+}
+
+// GetInstance 获取主库底层实例
+func (c *Cluster) GetInstance() interface{} {
+	return c.primary.GetInstance()
+}
+
+// GetStats 获取主从库连接统计之和
+func (c *Cluster) GetStats() ConnectionStats {
+	stats := c.primary.GetStats()
+	for _, replica := range c.replicas {
+		s := replica.adapter.GetStats()
+		stats.OpenConnections += s.OpenConnections
+		stats.InUse += s.InUse
+		stats.Idle += s.Idle
+		stats.WaitCount += s.WaitCount
+		stats.WaitDuration += s.WaitDuration
+		stats.MaxIdleClosed += s.MaxIdleClosed
+		stats.MaxLifetimeClosed += s.MaxLifetimeClosed
+	}
+	return stats
+}
+
+// BatchInsert 批量插入 (主库)
+func (c *Cluster) BatchInsert(ctx context.Context, table string, data []map[string]interface{}) error {
+	defer c.markWrite(ctx)
+	return c.primary.BatchInsert(ctx, table, data)
+}
+
+// BatchUpdate 批量更新 (主库)
+func (c *Cluster) BatchUpdate(ctx context.Context, table string, data []map[string]interface{}, whereColumns []string) error {
+	defer c.markWrite(ctx)
+	return c.primary.BatchUpdate(ctx, table, data, whereColumns)
+}
+
+func (c *Cluster) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	replica := c.readReplica(ctx, query)
+	if replica == nil {
+		if !isReadQuery(query) {
+			// INSERT ... RETURNING 等经由 Query 执行的写入
+			defer c.markWrite(ctx)
+		}
+		return c.primary.QueryContext(ctx, query, args...)
+	}
+	start := time.Now()
+	rows, err := replica.adapter.QueryContext(ctx, query, args...)
+	replica.observe(time.Since(start), err)
+	if err != nil && ctx.Err() == nil {
+		rows, err = c.primary.QueryContext(ctx, query, args...)
+		if err != nil {
+			replica.restore()
+		}
+	}
+	return rows, err
+}
+
+func (c *Cluster) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	replica := c.readReplica(ctx, query)
+	if replica == nil {
+		if !isReadQuery(query) {
+			defer c.markWrite(ctx)
+		}
+		return c.primary.QueryRowContext(ctx, query, args...)
+	}
+	start := time.Now()
+	row := replica.adapter.QueryRowContext(ctx, query, args...)
+	replica.observe(time.Since(start), row.Err())
+	if row.Err() != nil && ctx.Err() == nil {
+		row = c.primary.QueryRowContext(ctx, query, args...)
+		if row.Err() != nil {
+			replica.restore()
+		}
+	}
+	return row
+}
+
+func (c *Cluster) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecContext(context.Background(), query, args...)
+}
+
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer c.markWrite(ctx)
+	return c.primary.ExecContext(ctx, query, args...)
+}
+
+// Begin 在主库开启事务，事务内所有语句在主库执行
+func (c *Cluster) Begin() (TransactionInterface, error) {
+	return c.BeginTx(context.Background(), nil)
+}
+
+// BeginTx 在主库开启事务，提交时开启 ctx 所属读写会话的读己之写窗口
+func (c *Cluster) BeginTx(ctx context.Context, opts *sql.TxOptions) (TransactionInterface, error) {
+	tx, err := c.primary.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if adapter, ok := tx.(UniversalAdapterInterface); ok {
+		return &clusterTx{UniversalAdapterInterface: adapter, cluster: c, ctx: ctx}, nil
+	}
+	return tx, nil
+}
+
+// Commit 集群本身不处于事务中
+func (c *Cluster) Commit() error {
+	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+}
+
+// Rollback 集群本身不处于事务中
+func (c *Cluster) Rollback() error {
+	return errors.NewError(errors.ErrorCodeBuilderNotInitialized, "not in a transaction")
+}
+
+// Prepare 在主库预处理，无法预知语句用途
+func (c *Cluster) Prepare(query string) (StatementInterface, error) {
+	return c.primary.Prepare(query)
+}
+
+func (c *Cluster) PrepareContext(ctx context.Context, query string) (StatementInterface, error) {
+	return c.primary.PrepareContext(ctx, query)
+}
+
+func (c *Cluster) Ping() error {
+	return c.PingContext(context.Background())
+}
+
+// PingContext 检测主库与所有从库
+func (c *Cluster) PingContext(ctx context.Context) error {
+	errs := []error{c.primary.PingContext(ctx)}
+	for _, replica := range c.replicas {
+		errs = append(errs, replica.adapter.PingContext(ctx))
+	}
+	return stderrors.Join(errs...)
+}
+
+// Close 关闭主库与所有从库
+func (c *Cluster) Close() error {
+	errs := []error{c.primary.Close()}
+	for _, replica := range c.replicas {
+		errs = append(errs, replica.adapter.Close())
+	}
+	return stderrors.Join(errs...)
+}
+
+// clusterTx 主库事务，提交成功后开启所属读写会话的读己之写窗口
+type clusterTx struct {
+	UniversalAdapterInterface
+	cluster *Cluster
+	ctx     context.Context
+}
+
+// Commit 提交事务
+func (t *clusterTx) Commit() error {
+	if err := t.UniversalAdapterInterface.Commit(); err != nil {
+		return err
+	}
+	t.cluster.markWrite(t.ctx)
+	return nil
+}
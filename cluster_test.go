@@ -0,0 +1,185 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 22:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 22:00:00
+ * @FilePath: \go-sqlbuilder\cluster_test.go
+ * @Description: 读写分离集群测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicaDB 创建从库，status 统一标记为 tag 以区分数据来源
+func newReplicaDB(t *testing.T, tag string) *sqlx.DB {
+	t.Helper()
+
+	db := newSQLiteDB(t)
+	_, err := db.Exec("UPDATE users SET status = ?", tag)
+	require.NoError(t, err)
+	return db
+}
+
+// readSource 读取第一行的 status，标识本次查询落在哪个库
+func readSource(t *testing.T, b *Builder) string {
+	t.Helper()
+
+	var status string
+	require.NoError(t, b.Table("users").Select("status").Where("id", "=", 1).First(&status))
+	return status
+}
+
+// TestCluster_Routing 测试读从库、写主库、事务与强制主库
+func TestCluster_Routing(t *testing.T) {
+	primary := newSQLiteDB(t)
+	replicas := []*sqlx.DB{newReplicaDB(t, "replica-1"), newReplicaDB(t, "replica-2")}
+	cluster, err := NewCluster(primary, replicas[0], replicas[1])
+	require.NoError(t, err)
+	b, err := New(cluster)
+	require.NoError(t, err)
+	assert.Equal(t, constant.DialectSQLite, b.GetDialect())
+
+	// 轮询从库
+	assert.Equal(t, "replica-1", readSource(t, b))
+	assert.Equal(t, "replica-2", readSource(t, b))
+	assert.Equal(t, "replica-1", readSource(t, b))
+
+	// 写入只落主库
+	insertTxUser(t, b, "Dave")
+	assert.Equal(t, 1, countTxUsers(t, primary, "Dave"))
+	for _, replica := range replicas {
+		assert.Equal(t, 0, countTxUsers(t, replica, "Dave"))
+	}
+
+	// 强制主库
+	assert.Equal(t, "active", readSource(t, b.WithContext(ForcePrimary(context.Background()))))
+
+	// 事务内的读在主库
+	require.NoError(t, b.Transaction(func(tx *Builder) error {
+		assert.Equal(t, "active", readSource(t, tx))
+		return nil
+	}))
+	require.NoError(t, cluster.Ping())
+	t.Logf("✓ 读写分离路由测试通过")
+}
+
+// TestCluster_ReadYourWrites 测试读写会话内写入后窗口期内读主库
+func TestCluster_ReadYourWrites(t *testing.T) {
+	primary := newSQLiteDB(t)
+	cluster, err := NewCluster(primary, newReplicaDB(t, "replica"))
+	require.NoError(t, err)
+	cluster.WithReadYourWrites(50 * time.Millisecond)
+	b, err := New(cluster)
+	require.NoError(t, err)
+	session := b.Clone().WithContext(WithWriteSession(context.Background()))
+	other := b.Clone().WithContext(WithWriteSession(context.Background()))
+
+	assert.Equal(t, "replica", readSource(t, session))
+	insertTxUser(t, session, "Dave")
+	assert.Equal(t, "active", readSource(t, session))
+	// 其他会话与无会话的读请求不受影响
+	assert.Equal(t, "replica", readSource(t, other))
+	assert.Equal(t, "replica", readSource(t, b))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, "replica", readSource(t, session))
+
+	// 无会话的写入不开启窗口
+	insertTxUser(t, b, "Frank")
+	assert.Equal(t, "replica", readSource(t, b))
+
+	// 事务提交同样开启窗口
+	require.NoError(t, session.Transaction(func(tx *Builder) error {
+		insertTxUser(t, tx, "Eve")
+		return nil
+	}))
+	assert.Equal(t, "active", readSource(t, session))
+	assert.Equal(t, "replica", readSource(t, other))
+	t.Logf("✓ 读己之写测试通过")
+}
+
+// TestCluster_ReplicaFailure 测试从库故障时回退主库并暂停使用该从库
+func TestCluster_ReplicaFailure(t *testing.T) {
+	broken := newReplicaDB(t, "broken")
+	cluster, err := NewCluster(newSQLiteDB(t), broken, newReplicaDB(t, "replica"))
+	require.NoError(t, err)
+	cluster.WithStrategy(ReplicaLeastLatency)
+	b, err := New(cluster)
+	require.NoError(t, err)
+	require.NoError(t, broken.Close())
+
+	// 故障从库的读请求在主库重试，之后不再被选中
+	assert.Equal(t, "active", readSource(t, b))
+	assert.False(t, cluster.replicas[0].available(time.Now()))
+	assert.Equal(t, "replica", readSource(t, b))
+	assert.Equal(t, "replica", readSource(t, b))
+
+	// 主库同样失败时错误原样返回，从库不被暂停
+	var status string
+	assert.Error(t, b.Table("missing").Select("status").First(&status))
+	assert.True(t, cluster.replicas[1].available(time.Now()))
+
+	// 所有从库不可用时读主库
+	cluster.replicas[1].observe(time.Millisecond, stderrors.New("connection refused"))
+	assert.Nil(t, cluster.readReplica(context.Background(), "SELECT 1"))
+	assert.Equal(t, "active", readSource(t, b))
+	t.Logf("✓ 从库故障回退测试通过")
+}
+
+// TestCluster_Strategy 测试从库选择策略与只读语句识别
+func TestCluster_Strategy(t *testing.T) {
+	cluster, err := NewCluster(newSQLiteDB(t), newReplicaDB(t, "replica-1"), newReplicaDB(t, "replica-2"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	query := "SELECT * FROM users"
+
+	cluster.WithStrategy(ReplicaLeastLatency)
+	cluster.replicas[0].observe(10*time.Millisecond, nil)
+	cluster.replicas[1].observe(time.Millisecond, nil)
+	assert.Same(t, cluster.replicas[1], cluster.readReplica(ctx, query))
+	cluster.replicas[1].observe(100*time.Millisecond, nil)
+	assert.Same(t, cluster.replicas[0], cluster.readReplica(ctx, query))
+
+	// 失败的查询不计入延迟，且暂停使用该从库
+	cluster.replicas[0].observe(time.Microsecond, stderrors.New("connection refused"))
+	assert.Same(t, cluster.replicas[1], cluster.readReplica(ctx, query))
+	cluster.replicas[0].restore()
+	cluster.replicas[1].observe(time.Microsecond, context.Canceled)
+	assert.True(t, cluster.replicas[1].available(time.Now()))
+
+	cluster.WithStrategy(ReplicaRandom)
+	assert.Contains(t, cluster.replicas, cluster.readReplica(ctx, query))
+
+	assert.True(t, isReadQuery("  SELECT id, updated_at FROM users WHERE id = ?"))
+	assert.True(t, isReadQuery("WITH t AS (SELECT 1) SELECT * FROM t"))
+	assert.True(t, isReadQuery("(SELECT 1) UNION (SELECT 2)"))
+	assert.False(t, isReadQuery("SELECT * FROM users FOR UPDATE"))
+	assert.False(t, isReadQuery("SELECT * FROM users LOCK IN SHARE MODE"))
+	assert.False(t, isReadQuery("SELECT * FROM users WITH (UPDLOCK, ROWLOCK)"))
+	assert.False(t, isReadQuery("WITH moved AS (DELETE FROM a RETURNING *) SELECT * FROM moved"))
+	assert.False(t, isReadQuery("INSERT INTO users (name) VALUES (?) RETURNING id"))
+	assert.False(t, isReadQuery("SELECT * INTO backup FROM users"))
+
+	_, err = NewCluster(nil)
+	assert.Error(t, err)
+
+	// 从库类型或方言与主库不一致
+	primary := newSQLiteDB(t)
+	_, err = NewCluster(primary, newParamGormDB(t, newSQLiteDB(t)))
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeAdapterNotSupported))
+	_, err = NewCluster(primary, sqlx.NewDb(newSQLiteDB(t).DB, "mysql"))
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeAdapterNotSupported))
+	t.Logf("✓ 从库选择策略测试通过")
+}
@@ -0,0 +1,380 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 11:00:00
+ * @FilePath: \go-sqlbuilder\cmd\sqlbuilder-gen\generate.go
+ * @Description: 解析模型结构体并生成列描述符代码
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+)
+
+// columnPackage 列描述符所在包
+const columnPackage = "github.com/kamalyes/go-sqlbuilder/column"
+
+// model 待生成的模型
+type model struct {
+	name    string
+	table   string
+	columns []modelColumn
+}
+
+// modelColumn 模型列
+type modelColumn struct {
+	field  string // Go 字段名
+	column string // 列名
+	goType string // 值类型 (去掉指针)
+}
+
+// source 同包的其他源文件，用于展开跨文件的嵌入结构体
+type source struct {
+	name string
+	src  []byte
+}
+
+// structDecl 结构体声明及其所在文件的导入
+type structDecl struct {
+	st      *ast.StructType
+	imports map[string]string
+}
+
+// generate 解析源文件并生成列描述符，types 为空时处理全部导出结构体
+// siblings 为同包的其他源文件，只用于解析嵌入结构体与 TableName，不为其生成代码
+func generate(filename string, src []byte, types []string, siblings ...source) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	structs := make(map[string]structDecl)
+	tables := make(map[string]string)
+	order := collectStructs(file, structs, tables)
+	for _, sibling := range siblings {
+		other, err := parser.ParseFile(fset, sibling.name, sibling.src, 0)
+		if err != nil {
+			return nil, err
+		}
+		if other.Name.Name == file.Name.Name {
+			collectStructs(other, structs, tables)
+		}
+	}
+
+	wanted := make(map[string]bool, len(types))
+	for _, name := range types {
+		if _, ok := structs[name]; !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, filename)
+		}
+		wanted[name] = true
+	}
+
+	usedImports := make(map[string]string)
+	var models []model
+	for _, name := range order {
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		if len(wanted) == 0 && !ast.IsExported(name) {
+			continue
+		}
+		m := model{name: name, table: tables[name]}
+		if m.table == "" {
+			m.table = column.TableName(name)
+		}
+		c := &collector{fset: fset, structs: structs, used: usedImports, seen: make(map[string]bool), fields: make(map[string]string)}
+		if err := c.collect(structs[name], "", ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		m.columns = c.columns
+		models = append(models, m)
+	}
+
+	return render(file.Name.Name, models, usedImports)
+}
+
+// collectStructs 记录文件中的结构体声明与 TableName，返回结构体的声明顺序
+func collectStructs(file *ast.File, structs map[string]structDecl, tables map[string]string) []string {
+	imports := fileImports(file)
+	var order []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil {
+				if _, exists := structs[ts.Name.Name]; !exists {
+					structs[ts.Name.Name] = structDecl{st: st, imports: imports}
+				}
+				order = append(order, ts.Name.Name)
+			}
+		}
+	}
+	for name, table := range tableNames(file) {
+		if _, exists := tables[name]; !exists {
+			tables[name] = table
+		}
+	}
+	return order
+}
+
+// collector 收集单个模型的列
+type collector struct {
+	fset    *token.FileSet
+	structs map[string]structDecl
+	used    map[string]string // 生成代码需要导入的包
+	seen    map[string]bool   // 已收集的列名 (小写)
+	fields  map[string]string // Go 字段名 -> 列名
+	columns []modelColumn
+}
+
+// collect 收集结构体列，外层字段优先；嵌入结构体在同包内展开，其他包的嵌入结构体无法解析时报错
+// prefix 为列名前缀，fieldPrefix 为由 embeddedPrefix 推导的 Go 字段名前缀 (home_ -> Home)
+func (c *collector) collect(decl structDecl, prefix, fieldPrefix string) error {
+	type embedded struct {
+		decl        structDecl
+		prefix      string
+		fieldPrefix string
+	}
+	var nested []embedded
+
+	for _, field := range decl.st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+
+		typeExpr := field.Type
+		if star, ok := typeExpr.(*ast.StarExpr); ok {
+			typeExpr = star.X
+		}
+
+		if len(field.Names) == 0 {
+			inner, err := c.embeddedStruct(typeExpr)
+			if err != nil {
+				return err
+			}
+			nested = append(nested, embedded{decl: inner, prefix: prefix, fieldPrefix: fieldPrefix})
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name, skip := column.NameOf(ident.Name, tag)
+			if skip {
+				continue
+			}
+
+			// gorm:"embedded;embeddedPrefix:xxx_"
+			if isEmbedded, embeddedPrefix := column.EmbeddedOf(tag); isEmbedded {
+				inner, err := c.embeddedStruct(typeExpr)
+				if err != nil {
+					return err
+				}
+				nested = append(nested, embedded{decl: inner, prefix: prefix + embeddedPrefix, fieldPrefix: fieldPrefix + camelCase(embeddedPrefix)})
+				continue
+			}
+
+			columnName := prefix + name
+			if c.seen[strings.ToLower(columnName)] {
+				continue
+			}
+			c.seen[strings.ToLower(columnName)] = true
+
+			fieldName := fieldPrefix + ident.Name
+			if other, exists := c.fields[fieldName]; exists {
+				return fmt.Errorf("columns %s and %s both map to field %s, use distinct embeddedPrefix values", other, columnName, fieldName)
+			}
+			c.fields[fieldName] = columnName
+
+			markImports(typeExpr, decl.imports, c.used)
+			c.columns = append(c.columns, modelColumn{
+				field:  fieldName,
+				column: columnName,
+				goType: exprString(c.fset, typeExpr),
+			})
+		}
+	}
+
+	for _, e := range nested {
+		if err := c.collect(e.decl, e.prefix, e.fieldPrefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embeddedStruct 解析嵌入字段的结构体声明，仅支持同包内的结构体
+func (c *collector) embeddedStruct(typeExpr ast.Expr) (structDecl, error) {
+	if ident, ok := typeExpr.(*ast.Ident); ok {
+		if decl, exists := c.structs[ident.Name]; exists {
+			return decl, nil
+		}
+	}
+	return structDecl{}, fmt.Errorf("cannot resolve embedded struct %s: only structs declared in the same package are supported, declare its fields explicitly", exprString(c.fset, typeExpr))
+}
+
+// render 生成代码并格式化
+func render(pkg string, models []model, imports map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by sqlbuilder-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+
+	// 标准库与第三方包分组
+	std := []string{}
+	third := []string{strconv.Quote(columnPackage)}
+	for name, path := range imports {
+		spec := strconv.Quote(path)
+		if name != pathBase(path) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			third = append(third, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(third)
+	for _, spec := range std {
+		fmt.Fprintf(&buf, "\t%s\n", spec)
+	}
+	if len(std) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, spec := range third {
+		fmt.Fprintf(&buf, "\t%s\n", spec)
+	}
+	buf.WriteString(")\n")
+
+	for _, m := range models {
+		typeName := lowerFirst(m.name) + "Columns"
+		varName := column.Pluralize(m.name)
+
+		fmt.Fprintf(&buf, "\n// %s %s 表的列描述符\ntype %s struct {\n", typeName, m.table, typeName)
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "\t%s column.Column[%s]\n", col.field, col.goType)
+		}
+		buf.WriteString("}\n")
+
+		fmt.Fprintf(&buf, "\n// TableName 表名\nfunc (%s) TableName() string {\n\treturn %q\n}\n", typeName, m.table)
+
+		fmt.Fprintf(&buf, "\n// %s %s 的列\nvar %s = %s{\n", varName, m.name, varName, typeName)
+		for _, col := range m.columns {
+			fmt.Fprintf(&buf, "\t%s: column.New[%s](%q),\n", col.field, col.goType, col.column)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// ==================== 辅助函数 ====================
+
+// tableNames 解析 func (T) TableName() string { return "xxx" }
+func tableNames(file *ast.File) map[string]string {
+	tables := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "TableName" || fn.Body == nil || len(fn.Body.List) != 1 {
+			continue
+		}
+		ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		lit, ok := ret.Results[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if ident, ok := recv.(*ast.Ident); ok {
+			if value, err := strconv.Unquote(lit.Value); err == nil {
+				tables[ident.Name] = value
+			}
+		}
+	}
+	return tables
+}
+
+// fileImports 包名 -> 导入路径
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := pathBase(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// markImports 记录类型表达式引用的包
+func markImports(expr ast.Expr, imports, used map[string]string) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				if path, exists := imports[pkg.Name]; exists {
+					used[pkg.Name] = path
+				}
+			}
+		}
+		return true
+	})
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, fset, expr)
+	return buf.String()
+}
+
+func pathBase(path string) string {
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		return path[idx+1:]
+	}
+	return path
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// camelCase 由 embeddedPrefix 推导 Go 字段名前缀: home_ -> Home, billing_addr_ -> BillingAddr
+func camelCase(prefix string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(prefix, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
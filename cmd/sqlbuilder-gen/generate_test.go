@@ -0,0 +1,157 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 11:00:00
+ * @FilePath: \go-sqlbuilder\cmd\sqlbuilder-gen\generate_test.go
+ * @Description: 列描述符生成测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package main
+
+import (
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modelSource = `package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Base struct {
+	ID        int64     ` + "`db:\"id\"`" + `
+	CreatedAt time.Time ` + "`gorm:\"column:created_at\"`" + `
+}
+
+type Address struct {
+	City string
+}
+
+type User struct {
+	Base
+	Name    string         ` + "`json:\"name\"`" + `
+	Email   sql.NullString ` + "`db:\"email_address\"`" + `
+	Age     *int
+	Home    Address        ` + "`gorm:\"embedded;embeddedPrefix:home_\"`" + `
+	Work    Address        ` + "`gorm:\"embedded;embeddedPrefix:work_\"`" + `
+	Secret  string         ` + "`db:\"-\"`" + `
+	private string
+}
+
+type Category struct {
+	ID int64
+}
+
+func (Category) TableName() string { return "t_category" }
+`
+
+// TestGenerate 测试生成列描述符
+func TestGenerate(t *testing.T) {
+	code, err := generate("models.go", []byte(modelSource), []string{"User", "Category"})
+	require.NoError(t, err)
+	out := string(code)
+
+	assert.Contains(t, out, "// Code generated by sqlbuilder-gen. DO NOT EDIT.")
+	assert.Contains(t, out, "package models")
+	assert.Contains(t, out, `"database/sql"`)
+	assert.Contains(t, out, `"time"`)
+	assert.Contains(t, out, `"github.com/kamalyes/go-sqlbuilder/column"`)
+
+	assert.Contains(t, out, `Name:      column.New[string]("name")`)
+	assert.Contains(t, out, `Email:     column.New[sql.NullString]("email_address")`)
+	assert.Contains(t, out, `Age:       column.New[int]("age")`)
+	assert.Contains(t, out, `HomeCity:  column.New[string]("home_city")`)
+	assert.Contains(t, out, `WorkCity:  column.New[string]("work_city")`)
+	assert.Contains(t, out, `ID:        column.New[int64]("id")`)
+	assert.Contains(t, out, `CreatedAt: column.New[time.Time]("created_at")`)
+	assert.NotContains(t, out, "Secret")
+	assert.NotContains(t, out, "private")
+
+	assert.Contains(t, out, "var Users = userColumns{")
+	assert.Contains(t, out, `return "users"`)
+	assert.Contains(t, out, "var Categories = categoryColumns{")
+	assert.Contains(t, out, `return "t_category"`)
+	t.Logf("✓ 列描述符生成测试通过")
+}
+
+// TestGenerate_AllExported 测试未指定类型时生成全部导出结构体
+func TestGenerate_AllExported(t *testing.T) {
+	code, err := generate("models.go", []byte(modelSource), nil)
+	require.NoError(t, err)
+	out := string(code)
+
+	assert.Contains(t, out, "var Bases = baseColumns{")
+	assert.Contains(t, out, "var Addresses = addressColumns{")
+	assert.Contains(t, out, "var Users = userColumns{")
+
+	_, err = generate("models.go", []byte(modelSource), []string{"Missing"})
+	assert.Error(t, err)
+	t.Logf("✓ 全部导出结构体生成测试通过")
+}
+
+// TestGenerate_Embedded 测试跨文件嵌入结构体、其他包嵌入结构体与字段名冲突
+func TestGenerate_Embedded(t *testing.T) {
+	order := []byte(`package models
+
+import "gorm.io/gorm"
+
+type Order struct {
+	Base
+	Total int64
+}
+
+type Invoice struct {
+	gorm.Model
+}
+
+type Shipment struct {
+	From Address ` + "`gorm:\"embedded\"`" + `
+	To   Address ` + "`gorm:\"embedded;embeddedPrefix:to_\"`" + `
+	City string  ` + "`db:\"city_name\"`" + `
+}
+`)
+	base := source{name: "models.go", src: []byte(modelSource)}
+
+	code, err := generate("order.go", order, []string{"Order"}, base)
+	require.NoError(t, err)
+	out := string(code)
+	assert.Contains(t, out, `ID:        column.New[int64]("id")`)
+	assert.Contains(t, out, `CreatedAt: column.New[time.Time]("created_at")`)
+	assert.Contains(t, out, `"time"`)
+	assert.Contains(t, out, `return "orders"`)
+	assert.NotContains(t, out, "userColumns")
+
+	_, err = generate("order.go", order, []string{"Order"})
+	assert.ErrorContains(t, err, "cannot resolve embedded struct Base")
+
+	_, err = generate("order.go", order, []string{"Invoice"}, base)
+	assert.ErrorContains(t, err, "cannot resolve embedded struct gorm.Model")
+
+	_, err = generate("order.go", order, []string{"Shipment"}, base)
+	assert.ErrorContains(t, err, "both map to field City")
+	t.Logf("✓ 嵌入结构体生成测试通过")
+}
+
+// TestNaming 测试命名规则
+func TestNaming(t *testing.T) {
+	assert.Equal(t, "created_at", column.SnakeCase("CreatedAt"))
+	assert.Equal(t, "user_id", column.SnakeCase("UserID"))
+	assert.Equal(t, "http_status", column.SnakeCase("HTTPStatus"))
+	assert.Equal(t, "Users", column.Pluralize("User"))
+	assert.Equal(t, "Categories", column.Pluralize("Category"))
+	assert.Equal(t, "Keys", column.Pluralize("Key"))
+	assert.Equal(t, "Boxes", column.Pluralize("Box"))
+	assert.Equal(t, "categories", column.TableName("Category"))
+	// 与 GORM 一致的不规则复数
+	assert.Equal(t, "people", column.TableName("Person"))
+	assert.Equal(t, "Children", column.Pluralize("Child"))
+	assert.Equal(t, "BillingAddr", camelCase("billing_addr_"))
+	t.Logf("✓ 命名规则测试通过")
+}
@@ -0,0 +1,101 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 11:00:00
+ * @FilePath: \go-sqlbuilder\cmd\sqlbuilder-gen\main.go
+ * @Description: sqlbuilder-gen - 根据模型结构体生成类型安全的列描述符
+ *
+ * 用法 (在模型文件中):
+ *
+ *	//go:generate go run github.com/kamalyes/go-sqlbuilder/cmd/sqlbuilder-gen -type User
+ *
+ * 生成 user_columns_gen.go，其中包含 Users.Email.Eq(v)、Users.CreatedAt.Between(a, b) 等描述符
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "逗号分隔的结构体名，为空时处理文件中全部导出结构体")
+	input := flag.String("file", os.Getenv("GOFILE"), "模型源文件，默认为 go:generate 所在文件")
+	output := flag.String("output", "", "输出文件，默认为 <file>_columns_gen.go")
+	flag.Parse()
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "sqlbuilder-gen: -file is required outside of go generate")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	for _, name := range strings.Split(*typeNames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			types = append(types, name)
+		}
+	}
+
+	src, err := os.ReadFile(*input)
+	if err != nil {
+		fatal(err)
+	}
+
+	target := *output
+	if target == "" {
+		target = strings.TrimSuffix(*input, filepath.Ext(*input)) + "_columns_gen.go"
+	}
+
+	siblings, err := siblingSources(*input, target)
+	if err != nil {
+		fatal(err)
+	}
+
+	code, err := generate(*input, src, types, siblings...)
+	if err != nil {
+		fatal(err)
+	}
+	if err := os.WriteFile(target, code, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+// siblingSources 读取同目录下的其他源文件 (不含测试文件与生成文件)
+func siblingSources(input, target string) ([]source, error) {
+	dir := filepath.Dir(input)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []source
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+			strings.HasSuffix(name, "_columns_gen.go") || sameFile(name, input) || sameFile(name, target) {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source{name: name, src: src})
+	}
+	return sources, nil
+}
+
+func sameFile(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "sqlbuilder-gen: %v\n", err)
+	os.Exit(1)
+}
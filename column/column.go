@@ -0,0 +1,143 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 11:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 11:00:00
+ * @FilePath: \go-sqlbuilder\column\column.go
+ * @Description: 类型安全的列描述符，由 cmd/sqlbuilder-gen 根据模型结构体生成
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package column
+
+import (
+	"reflect"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+)
+
+// Condition 单列过滤条件，可传给 Builder.WhereCond / query.Param.AddCondition / repository.NewConditionFilter
+// IN / NOT IN 的 Value 为 []interface{}，BETWEEN 的 Value 为 []interface{}{min, max}
+type Condition struct {
+	Field    string
+	Operator constant.Operator
+	Value    interface{}
+}
+
+// Column 值类型为 T 的列
+type Column[T any] struct {
+	name string
+}
+
+// New 创建列描述符
+func New[T any](name string) Column[T] {
+	return Column[T]{name: name}
+}
+
+// Name 列名
+func (c Column[T]) Name() string {
+	return c.name
+}
+
+// String 列名
+func (c Column[T]) String() string {
+	return c.name
+}
+
+// Eq column = value
+func (c Column[T]) Eq(value T) Condition {
+	return c.cond(constant.OP_EQ, value)
+}
+
+// Neq column != value
+func (c Column[T]) Neq(value T) Condition {
+	return c.cond(constant.OP_NEQ, value)
+}
+
+// Gt column > value
+func (c Column[T]) Gt(value T) Condition {
+	return c.cond(constant.OP_GT, value)
+}
+
+// Gte column >= value
+func (c Column[T]) Gte(value T) Condition {
+	return c.cond(constant.OP_GTE, value)
+}
+
+// Lt column < value
+func (c Column[T]) Lt(value T) Condition {
+	return c.cond(constant.OP_LT, value)
+}
+
+// Lte column <= value
+func (c Column[T]) Lte(value T) Condition {
+	return c.cond(constant.OP_LTE, value)
+}
+
+// In column IN (values...)
+func (c Column[T]) In(values ...T) Condition {
+	return c.cond(constant.OP_IN, toInterfaces(values))
+}
+
+// NotIn column NOT IN (values...)
+func (c Column[T]) NotIn(values ...T) Condition {
+	return c.cond(constant.OP_NOT_IN, toInterfaces(values))
+}
+
+// Between column BETWEEN min AND max
+func (c Column[T]) Between(min, max T) Condition {
+	return c.cond(constant.OP_BETWEEN, []interface{}{min, max})
+}
+
+// Like column LIKE pattern (通配符由调用方提供)
+func (c Column[T]) Like(pattern string) Condition {
+	return c.cond(constant.OP_LIKE, pattern)
+}
+
+// Contains column LIKE %value%
+func (c Column[T]) Contains(value string) Condition {
+	return c.cond(constant.OP_LIKE, "%"+value+"%")
+}
+
+// IsNull column IS NULL
+func (c Column[T]) IsNull() Condition {
+	return c.cond(constant.OP_IS_NULL, nil)
+}
+
+// IsNotNull column IS NOT NULL
+func (c Column[T]) IsNotNull() Condition {
+	return c.cond(constant.OP_IS_NOT_NULL, nil)
+}
+
+func (c Column[T]) cond(operator constant.Operator, value interface{}) Condition {
+	return Condition{Field: c.name, Operator: operator, Value: value}
+}
+
+func toInterfaces[T any](values []T) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// Values 将 IN / BETWEEN 的条件值展开为 []interface{}
+// 切片、数组逐个展开，[]byte 与非集合值包装为单元素切片，nil 返回空
+func Values(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values
+}
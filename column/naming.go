@@ -0,0 +1,108 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-19 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-19 10:00:00
+ * @FilePath: \go-sqlbuilder\column\naming.go
+ * @Description: 结构体到表名 / 列名的命名约定，供构建器与 cmd/sqlbuilder-gen 共用
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package column
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+// NameOf 按 db > gorm column > json > snake_case 的优先级解析字段的列名，skip 为 true 表示字段标记为 "-"
+func NameOf(fieldName string, tag reflect.StructTag) (name string, skip bool) {
+	if value, ok := tag.Lookup("db"); ok {
+		name := strings.Split(value, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+
+	if value, ok := tag.Lookup("gorm"); ok {
+		if value == "-" {
+			return "", true
+		}
+		for _, part := range strings.Split(value, ";") {
+			kv := strings.SplitN(strings.TrimSpace(part), ":", 2)
+			if len(kv) == 2 && strings.EqualFold(kv[0], "column") {
+				return strings.TrimSpace(kv[1]), false
+			}
+		}
+	}
+
+	if value, ok := tag.Lookup("json"); ok {
+		name := strings.Split(value, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+
+	return SnakeCase(fieldName), false
+}
+
+// HasExplicitName 是否通过 db 或 gorm column 显式声明了列名 (显式声明的匿名字段不展开)
+func HasExplicitName(tag reflect.StructTag) bool {
+	if value := tag.Get("db"); value != "" && value != "-" {
+		return true
+	}
+	return strings.Contains(tag.Get("gorm"), "column:")
+}
+
+// EmbeddedOf 解析 gorm:"embedded;embeddedPrefix:xxx_"
+func EmbeddedOf(tag reflect.StructTag) (embedded bool, prefix string) {
+	for _, part := range strings.Split(tag.Get("gorm"), ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), ":", 2)
+		switch strings.ToLower(kv[0]) {
+		case "embedded":
+			embedded = true
+		case "embeddedprefix":
+			if len(kv) == 2 {
+				prefix = kv[1]
+			}
+		}
+	}
+	return embedded, prefix
+}
+
+// TableName 由类型名推导表名: Category -> categories，与 GORM 默认约定一致
+func TableName(typeName string) string {
+	return Pluralize(SnakeCase(typeName))
+}
+
+// Pluralize 英文复数，与 GORM 默认命名策略同样使用 inflection: category -> categories, person -> people
+func Pluralize(name string) string {
+	return inflection.Plural(name)
+}
+
+// SnakeCase CreatedAt -> created_at, UserID -> user_id
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	sb.Grow(len(name) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
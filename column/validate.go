@@ -0,0 +1,113 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 14:00:00
+ * @FilePath: \go-sqlbuilder\column\validate.go
+ * @Description: 标识符与操作符校验 - 防止经由字段名/排序字段的 SQL 注入
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package column
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// IdentifierPattern 默认标识符规则: name / table.name
+var IdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Validator 标识符校验器: 先按正则校验，设置白名单后还需在白名单内
+// nil 校验器按默认正则校验且不限制白名单
+type Validator struct {
+	pattern *regexp.Regexp
+	allowed map[string]struct{}
+}
+
+// NewValidator 创建校验器，allowed 为允许的列名 (不区分大小写)，为空时不限制
+func NewValidator(allowed ...string) *Validator {
+	return (&Validator{}).Allow(allowed...)
+}
+
+// WithPattern 返回使用自定义正则的校验器副本
+func (v *Validator) WithPattern(pattern *regexp.Regexp) *Validator {
+	clone := v.clone()
+	clone.pattern = pattern
+	return clone
+}
+
+// Allow 返回追加白名单列的校验器副本
+func (v *Validator) Allow(columns ...string) *Validator {
+	clone := v.clone()
+	for _, col := range columns {
+		if clone.allowed == nil {
+			clone.allowed = make(map[string]struct{}, len(columns))
+		}
+		clone.allowed[strings.ToLower(col)] = struct{}{}
+	}
+	return clone
+}
+
+// ValidateName 校验列名，table.column 形式时白名单匹配完整名称或列名部分
+func (v *Validator) ValidateName(name string) error {
+	if err := v.ValidateTable(name); err != nil {
+		return err
+	}
+	if v == nil || len(v.allowed) == 0 {
+		return nil
+	}
+
+	lower := strings.ToLower(name)
+	if _, ok := v.allowed[lower]; ok {
+		return nil
+	}
+	if idx := strings.LastIndex(lower, "."); idx >= 0 {
+		if _, ok := v.allowed[lower[idx+1:]]; ok {
+			return nil
+		}
+	}
+	return errors.NewErrorf(errors.ErrorCodeInvalidFieldName, errors.MsgIdentifierNotAllowed, name)
+}
+
+// ValidateTable 仅按正则校验 (表名不受列白名单限制)
+func (v *Validator) ValidateTable(name string) error {
+	pattern := IdentifierPattern
+	if v != nil && v.pattern != nil {
+		pattern = v.pattern
+	}
+	if !pattern.MatchString(name) {
+		return errors.NewErrorf(errors.ErrorCodeInvalidFieldName, errors.MsgInvalidIdentifier, name)
+	}
+	return nil
+}
+
+func (v *Validator) clone() *Validator {
+	if v == nil {
+		return &Validator{}
+	}
+	clone := &Validator{pattern: v.pattern}
+	if v.allowed != nil {
+		clone.allowed = make(map[string]struct{}, len(v.allowed))
+		for k := range v.allowed {
+			clone.allowed[k] = struct{}{}
+		}
+	}
+	return clone
+}
+
+// ParseOperator 按 constant.OperatorMap / CompatOperatorMap 解析操作符 (不区分大小写)
+// 未知操作符返回 ErrorCodeInvalidOperator
+func ParseOperator(operator string) (constant.Operator, error) {
+	op := strings.TrimSpace(operator)
+	if std, ok := constant.OperatorMap[strings.ToUpper(op)]; ok {
+		return std, nil
+	}
+	if compat, ok := constant.CompatOperatorMap[strings.ToLower(op)]; ok {
+		return compat, nil
+	}
+	return "", errors.NewErrorf(errors.ErrorCodeInvalidOperator, errors.MsgUnknownOperator, operator)
+}
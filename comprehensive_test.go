@@ -500,3 +500,122 @@ func TestBuilderMultipleWheres(t *testing.T) {
 
 	t.Logf("✓ Multiple WHERE SQL: %s", sql)
 }
+
+// TestBuilderInsertDeterministic 测试INSERT列顺序稳定且ToSQL可重复调用
+func TestBuilderInsertDeterministic(t *testing.T) {
+	builder := &Builder{
+		adapter: nil,
+		ctx:     context.Background(),
+	}
+
+	data := map[string]interface{}{
+		"name":  "Alice",
+		"email": "alice@example.com",
+		"age":   30,
+	}
+
+	builder.Table("users").Insert(data)
+	sql1, args1 := builder.ToSQL()
+	sql2, args2 := builder.ToSQL()
+
+	assert.Equal(t, "INSERT INTO users (age, email, name) VALUES (?, ?, ?)", sql1)
+	assert.Equal(t, []interface{}{30, "alice@example.com", "Alice"}, args1)
+	assert.Equal(t, sql1, sql2, "Expected stable SQL.")
+	assert.Equal(t, args1, args2, "Expected args not to accumulate.")
+
+	t.Logf("✓ Deterministic INSERT SQL: %s", sql1)
+}
+
+// TestBuilderInsertColumns 测试按指定列顺序插入
+func TestBuilderInsertColumns(t *testing.T) {
+	builder := &Builder{
+		adapter: nil,
+		ctx:     context.Background(),
+	}
+
+	sql, args := builder.
+		Table("users").
+		InsertColumns([]string{"name", "email", "age"}, []interface{}{"Bob", "bob@example.com", 25}).
+		ToSQL()
+
+	assert.Equal(t, "INSERT INTO users (name, email, age) VALUES (?, ?, ?)", sql)
+	assert.Equal(t, []interface{}{"Bob", "bob@example.com", 25}, args)
+
+	t.Logf("✓ InsertColumns SQL: %s", sql)
+}
+
+// TestBuilderUpdateArgsOrder 测试UPDATE参数顺序 (SET 在 WHERE 之前)
+func TestBuilderUpdateArgsOrder(t *testing.T) {
+	builder := &Builder{
+		adapter: nil,
+		ctx:     context.Background(),
+	}
+
+	sql, args := builder.
+		Table("users").
+		Where("id", "=", 1).
+		Set("status", "active").
+		Set("age", 35).
+		ToSQL()
+
+	assert.Equal(t, "UPDATE users SET status = ?, age = ? WHERE id = ?", sql)
+	assert.Equal(t, []interface{}{"active", 35, 1}, args)
+
+	b2, _ := (&Builder{ctx: context.Background()}).Table("users").Update(map[string]interface{}{"b": 2, "a": 1})
+	sql, args = b2.ToSQL()
+	assert.Equal(t, "UPDATE users SET a = ?, b = ?", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	t.Logf("✓ UPDATE SQL: %s with args %v", sql, args)
+}
+
+// TestBuilderClone 测试克隆后互不影响
+func TestBuilderClone(t *testing.T) {
+	base := &Builder{
+		adapter: nil,
+		ctx:     context.Background(),
+	}
+	base.Table("users").Where("status", "=", "active")
+
+	clone := base.Clone().Where("age", ">", 18).OrderBy("id")
+
+	baseSQL, baseArgs := base.ToSQL()
+	cloneSQL, cloneArgs := clone.ToSQL()
+
+	assert.NotSame(t, base, clone)
+	assert.Equal(t, "SELECT * FROM users WHERE status = ?", baseSQL)
+	assert.Len(t, baseArgs, 1)
+	assert.Equal(t, "SELECT * FROM users WHERE status = ? AND age > ? ORDER BY id ASC", cloneSQL)
+	assert.Len(t, cloneArgs, 2)
+
+	t.Logf("✓ Clone SQL: %s", cloneSQL)
+}
+
+// TestBuilderImmutable 测试不可变模式下链式调用返回新实例
+func TestBuilderImmutable(t *testing.T) {
+	base := (&Builder{
+		adapter: nil,
+		ctx:     context.Background(),
+	}).Table("users").Where("tenant_id", "=", 1).Immutable()
+
+	admins := base.Where("role", "=", "admin").Limit(5)
+	guests := base.Where("role", "=", "guest")
+
+	baseSQL, baseArgs := base.ToSQL()
+	adminSQL, adminArgs := admins.ToSQL()
+	guestSQL, guestArgs := guests.ToSQL()
+
+	assert.True(t, base.IsImmutable())
+	assert.NotSame(t, base, admins)
+	assert.Equal(t, "SELECT * FROM users WHERE tenant_id = ?", baseSQL)
+	assert.Equal(t, []interface{}{1}, baseArgs)
+	assert.Equal(t, "SELECT * FROM users WHERE tenant_id = ? AND role = ? LIMIT 5", adminSQL)
+	assert.Equal(t, []interface{}{1, "admin"}, adminArgs)
+	assert.Equal(t, "SELECT * FROM users WHERE tenant_id = ? AND role = ?", guestSQL)
+	assert.Equal(t, []interface{}{1, "guest"}, guestArgs)
+
+	mutable := base.Mutable()
+	assert.Same(t, mutable, mutable.Where("id", "=", 2))
+
+	t.Logf("✓ Immutable SQL: %s", adminSQL)
+}
@@ -0,0 +1,57 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 10:00:00
+ * @FilePath: \go-sqlbuilder\core\convert.go
+ * @Description: 转换为规范的 query.Param
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package core
+
+import (
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+)
+
+// ToParam 转换为 query.Param，可交由 Builder.ApplyParam 或 Param.GormScope 执行
+// 未设置 Pagination 时 PageSize 为 0，即不分页
+func (c *QueryCondition) ToParam() (*query.Param, error) {
+	if c == nil {
+		return FiltersToParam(nil)
+	}
+	param, err := FiltersToParam(c.Filters)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range c.Orders {
+		param.AddOrder(order.Field, order.Order)
+	}
+	if c.Pagination != nil {
+		param.Page = c.Pagination.Page
+		param.PageSize = c.Pagination.PageSize
+		param.Offset = c.Pagination.Offset
+		param.Limit = c.Pagination.Limit
+	}
+	return param, nil
+}
+
+// FiltersToParam 将过滤条件以 AND 连接转换为 query.Param (不分页)
+func FiltersToParam(filters []Filter) (*query.Param, error) {
+	param := query.NewParam()
+	param.PageSize = 0
+	for _, filter := range filters {
+		if filter.Field == "" {
+			return nil, errors.NewError(errors.ErrorCodeInvalidFilterValue, constant.ErrFilterFieldEmpty)
+		}
+		op, err := column.ParseOperator(string(filter.Operator))
+		if err != nil {
+			return nil, err
+		}
+		param.AddFilter(filter.Field, query.Operator(op), filter.Value)
+	}
+	return param, nil
+}
@@ -0,0 +1,166 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 20:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 20:00:00
+ * @FilePath: \go-sqlbuilder\db_error_test.go
+ * @Description: 驱动错误分类测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/unified"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgError 模拟 pgx 错误
+type pgError struct {
+	state string
+	msg   string
+}
+
+func (e *pgError) Error() string    { return fmt.Sprintf("ERROR: %s (SQLSTATE %s)", e.msg, e.state) }
+func (e *pgError) SQLState() string { return e.state }
+
+// TestClassifyDBError_SQLite 测试 SQLite 真实约束错误的分类
+func TestClassifyDBError_SQLite(t *testing.T) {
+	db := newSQLiteDB(t)
+	b := newSQLiteBuilder(t, db)
+
+	_, rawErr := b.Table("users").Insert(map[string]interface{}{"id": 1, "name": "Dup"}).Exec()
+	require.Error(t, rawErr)
+	assert.True(t, unified.IsDuplicate(rawErr))
+
+	err := unified.ClassifyDBError(rawErr)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeDBDuplicate))
+	assert.True(t, unified.IsDuplicate(err))
+	assert.ErrorIs(t, err, rawErr)
+	var appErr *unified.BusinessError
+	require.True(t, stderrors.As(err, &appErr))
+	assert.Equal(t, "id", appErr.Constraint)
+
+	_, rawErr = b.Table("users").Insert(map[string]interface{}{"email": "x@example.com"}).Exec()
+	err = unified.ClassifyDBError(rawErr)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeDBConstraint))
+	require.True(t, stderrors.As(err, &appErr))
+	assert.Equal(t, "name", appErr.Constraint)
+	assert.False(t, unified.IsDuplicate(err))
+	t.Logf("✓ SQLite 错误分类测试通过")
+}
+
+// TestClassifyDBError_Drivers 测试 MySQL / PostgreSQL 错误分类与约束名提取
+func TestClassifyDBError_Drivers(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       errors.ErrorCode
+		constraint string
+		fk         bool
+		retryable  bool
+	}{
+		{
+			name:       "mysql duplicate",
+			err:        &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@x.com' for key 'users.idx_email'"},
+			code:       errors.ErrorCodeDBDuplicate,
+			constraint: "idx_email",
+		},
+		{
+			name:       "mysql foreign key",
+			err:        &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`shop`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			code:       errors.ErrorCodeDBConstraint,
+			constraint: "fk_orders_user",
+			fk:         true,
+		},
+		{
+			name:       "mysql not null",
+			err:        &mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"},
+			code:       errors.ErrorCodeDBConstraint,
+			constraint: "name",
+		},
+		{
+			name:      "mysql deadlock",
+			err:       fmt.Errorf("update stock: %w", &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}),
+			code:      errors.ErrorCodeDBDeadlock,
+			retryable: true,
+		},
+		{
+			name:       "postgres unique",
+			err:        &pgError{state: "23505", msg: `duplicate key value violates unique constraint "users_email_key"`},
+			code:       errors.ErrorCodeDBDuplicate,
+			constraint: "users_email_key",
+		},
+		{
+			name:       "postgres foreign key",
+			err:        &pgError{state: "23503", msg: `insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`},
+			code:       errors.ErrorCodeDBConstraint,
+			constraint: "orders_user_id_fkey",
+			fk:         true,
+		},
+		{
+			name:      "postgres serialization",
+			err:       &pgError{state: "40001", msg: "could not serialize access due to concurrent update"},
+			code:      errors.ErrorCodeDBDeadlock,
+			retryable: true,
+		},
+		{
+			name:      "sqlite busy",
+			err:       fmt.Errorf("commit: %w", sqlite3.Error{Code: sqlite3.ErrBusy}),
+			code:      errors.ErrorCodeDBDeadlock,
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := unified.ClassifyDBError(tt.err)
+			var appErr *unified.BusinessError
+			require.True(t, stderrors.As(err, &appErr))
+			assert.Equal(t, tt.code, appErr.Code)
+			assert.Equal(t, tt.constraint, appErr.Constraint)
+			assert.Equal(t, tt.err, appErr.Unwrap())
+			assert.Equal(t, tt.code == errors.ErrorCodeDBDuplicate, unified.IsDuplicate(err))
+			assert.Equal(t, tt.fk, unified.IsForeignKeyViolation(err))
+			assert.Equal(t, tt.retryable, unified.IsRetryable(err))
+		})
+	}
+
+	// 无法识别或已分类的错误原样返回
+	plain := stderrors.New("connection refused")
+	assert.Equal(t, plain, unified.ClassifyDBError(plain))
+	assert.Nil(t, unified.ClassifyDBError(nil))
+	notFound := errors.NewNotFound("user")
+	assert.Equal(t, error(notFound), unified.ClassifyDBError(notFound))
+	assert.False(t, unified.IsRetryable(&pgError{state: "23505"}))
+
+	// SQLite 错误信息只在 SQLite 驱动错误上识别
+	foreign := fmt.Errorf("sync users: %w", stderrors.New("UNIQUE constraint failed: users.email"))
+	assert.Equal(t, foreign, unified.ClassifyDBError(foreign))
+	assert.False(t, unified.IsDuplicate(foreign))
+	assert.False(t, unified.IsRetryable(stderrors.New("database is locked")))
+
+	// 已包装为 AppError 的驱动错误按 cause 分类
+	dup := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@x.com' for key 'users.idx_email'"}
+	wrapped := errors.NewDatabaseError("insert", dup)
+	err := unified.ClassifyDBError(wrapped)
+	assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeDBDuplicate))
+	assert.ErrorIs(t, err, dup)
+	assert.Equal(t, error(wrapped), stderrors.Unwrap(err))
+	classified := unified.ClassifyDBError(dup)
+	assert.Equal(t, classified, unified.ClassifyDBError(classified))
+
+	// 仅有错误代码的约束错误
+	assert.True(t, unified.IsForeignKeyViolation(errors.NewError(errors.ErrorCodeDBConstraint, "orders_user_id_fkey")))
+	notNull := errors.Wrap(&mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"}, errors.ErrorCodeDBConstraint)
+	assert.False(t, unified.IsForeignKeyViolation(notNull))
+	t.Logf("✓ 驱动错误分类测试通过")
+}
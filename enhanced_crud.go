@@ -329,20 +329,9 @@ func (eb *EnhancedBuilder) BatchUpsert(ctx context.Context, data []map[string]in
 		eb.addAuditFields(item, constant.OperationTypeUpsert)
 	}
 
-	// 如果适配器支持原生upsert，使用批量操作
-	if eb.adapter.SupportsUpsert() {
-		return eb.adapter.BatchInsert(ctx, eb.table, data)
-	}
-
-	// 否则使用事务逐条处理
-	return eb.Transaction(func(tx *Builder) error {
-		for _, item := range data {
-			if err := eb.upsertSingle(ctx, tx, item, conflictFields); err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+	// 原生 UPSERT 单条语句完成，避免先查后写的并发竞争
+	_, err := eb.Builder.Clone().WithContext(ctx).Table(eb.table).UpsertBatch(data, conflictFields, nil).Exec()
+	return err
 }
 
 // ==================== 辅助方法 ====================
@@ -413,37 +402,6 @@ func (eb *EnhancedBuilder) applyFilter(query *Builder, filter *EnhancedFilter) *
 	return query
 }
 
-func (eb *EnhancedBuilder) upsertSingle(ctx context.Context, tx *Builder, data map[string]interface{}, conflictFields []string) error {
-	// 检查记录是否存在
-	existsQuery := tx.Table(eb.table)
-	for _, field := range conflictFields {
-		if value, ok := data[field]; ok {
-			existsQuery = existsQuery.Where(field, "=", value)
-		}
-	}
-
-	exists, err := existsQuery.Exists()
-	if err != nil {
-		return err
-	}
-
-	if exists {
-		// 更新现有记录
-		updateQuery := tx.Table(eb.table)
-		for _, field := range conflictFields {
-			if value, ok := data[field]; ok {
-				updateQuery = updateQuery.Where(field, "=", value)
-			}
-		}
-		_, err = updateQuery.Update(data)
-	} else {
-		// 插入新记录
-		_, err = tx.Table(eb.table).Insert(data).Exec()
-	}
-
-	return err
-}
-
 // ==================== 数据结构定义 ====================
 
 // CreateOptions 创建选项
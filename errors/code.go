@@ -113,6 +113,10 @@ const (
 	// 数据库相关消息
 	MsgNoDatabaseConnection       = "no database connection available"
 	MsgCannotBeginNestedTransaction = "cannot begin transaction within transaction"
+	MsgSavepointFailed            = "savepoint %s: %v"
+	MsgTxRetryExhausted           = "transaction aborted after %d attempts: %v"
+	MsgTxContextMismatch          = "cannot join context transaction: %s"
+	MsgReplicaMismatch            = "replica %d (%s/%s) does not match primary (%s/%s)"
 	MsgDatabaseOperationFailed    = "database operation failed"
 	MsgFailedToExecuteUpdate      = "failed to execute update"
 	MsgFailedToExecuteDelete      = "failed to execute delete"
@@ -158,6 +162,8 @@ const (
 	MsgGormNotSupportPrepare      = "gorm does not support prepared statements directly"
 	MsgGormNotSupportLastInsertId = "gorm does not support LastInsertId, use returning clause"
 	MsgPostgresNotSupportLastInsertId = "postgres does not support LastInsertId, use RETURNING clause"
+	MsgSQLServerNotSupportLastInsertId = "sqlserver does not support LastInsertId, use OUTPUT clause"
+	MsgReturningNotSupported      = "returning rows is not supported for this statement on current dialect"
 	MsgUnknownAdapter             = "unknown adapter: %s"
 	MsgUnsupportedDatabaseInstance = "unsupported database instance type"
 
@@ -172,4 +178,30 @@ const (
 	MsgBatchSetEncounteredErrors  = "batch set encountered errors"
 	MsgUnsupportedDBType          = "unsupported db type: %s"
 	MsgDestMustBePointer          = "dest must be a pointer"
+	MsgChunkSizeInvalid           = "chunk size must be greater than 0"
+	MsgChunkKeyNotFound           = "chunk key column %s not found in result"
+	MsgKeysetColumnsRequired      = "cursor pagination requires OrderByKey columns"
+	MsgCursorLimitInvalid         = "cursor page limit must be greater than 0"
+	MsgInvalidCursor              = "invalid or tampered cursor"
+	MsgPrimaryKeyRequired         = "primary key field is required"
+	MsgPrimaryKeyUnreachable      = "primary key %s is behind a nil embedded struct"
+	MsgInvalidIdentifier          = "invalid identifier: %s"
+	MsgIdentifierNotAllowed       = "identifier not allowed: %s"
+	MsgInvalidWindowFrame         = "invalid window frame: %s"
+	MsgBetweenValuesInvalid       = "BETWEEN on %s requires exactly 2 values, got %d"
+	MsgUpsertConflictRequired     = "upsert requires conflict columns to update on conflict"
+	MsgUnknownOperator            = "unknown operator: %s"
+	MsgInvalidSortOrder           = "invalid sort order: %s"
+	MsgUnknownQueryParam          = "unknown query parameter: %s"
+	MsgOperatorNotAllowed         = "operator %s is not allowed for %s"
+	MsgInvalidParamValue          = "invalid value %q for %s, expected %s"
+	MsgFieldNotSortable           = "field %s is not sortable"
+	MsgPageNumberInvalid          = "page must be a positive integer"
+	MsgPageSizeInvalid            = "page_size must be between 1 and %d"
+	MsgInvalidQueryParams         = "invalid query parameters"
+	MsgFilterNodeInvalid          = "filter %s must contain exactly one of and, or, field"
+	MsgFilterFieldInvalid         = "filter %s has invalid field %q"
+	MsgFilterOperatorInvalid      = "filter %s has unknown operator %q"
+	MsgFilterValueInvalid         = "filter %s has invalid value for %s"
+	MsgFilterNotConvertible       = "%T cannot be converted to query.Param"
 )
\ No newline at end of file
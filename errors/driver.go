@@ -0,0 +1,281 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 20:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 20:00:00
+ * @FilePath: \go-sqlbuilder\errors\driver.go
+ * @Description: 数据库驱动错误分类 - MySQL / PostgreSQL (pq, pgx) / SQLite
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package errors
+
+import (
+	stderrors "errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// dbErrorKind 驱动错误类别
+type dbErrorKind int
+
+const (
+	dbErrorUnknown    dbErrorKind = iota
+	dbErrorDuplicate              // 唯一约束 / 主键冲突
+	dbErrorForeignKey             // 外键约束
+	dbErrorNotNull                // 非空约束
+	dbErrorCheck                  // CHECK 约束
+	dbErrorRetryable              // 死锁、锁等待超时、序列化失败、SQLite BUSY
+)
+
+// MySQL 错误号
+const (
+	mysqlErrDupEntry            = 1062
+	mysqlErrDupEntryWithKeyName = 1586
+	mysqlErrNoReferencedRow     = 1216
+	mysqlErrRowIsReferenced     = 1217
+	mysqlErrRowIsReferenced2    = 1451
+	mysqlErrNoReferencedRow2    = 1452
+	mysqlErrBadNull             = 1048
+	mysqlErrCheckViolated       = 3819
+	mysqlErrLockWaitTimeout     = 1205
+	mysqlErrLockDeadlock        = 1213
+)
+
+// PostgreSQL SQLSTATE
+const (
+	pgStateUniqueViolation     = "23505"
+	pgStateForeignKeyViolation = "23503"
+	pgStateNotNullViolation    = "23502"
+	pgStateCheckViolation      = "23514"
+	pgStateSerializationFail   = "40001"
+	pgStateDeadlockDetected    = "40P01"
+)
+
+// SQLite 扩展错误码
+const (
+	sqliteErrBusy              = 5
+	sqliteErrConstraintCheck   = 275
+	sqliteErrConstraintFK      = 787
+	sqliteErrConstraintNotNull = 1299
+	sqliteErrConstraintPK      = 1555
+	sqliteErrConstraintUnique  = 2067
+)
+
+var (
+	mysqlKeyPattern        = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlConstraintPattern = regexp.MustCompile("CONSTRAINT [`']([^`']+)[`']")
+	mysqlColumnPattern     = regexp.MustCompile(`Column '([^']+)'`)
+	pgConstraintPattern    = regexp.MustCompile(`constraint "([^"]+)"`)
+	pgColumnPattern        = regexp.MustCompile(`column "([^"]+)"`)
+	sqlitePattern          = regexp.MustCompile(`(UNIQUE|NOT NULL|CHECK|FOREIGN KEY) constraint failed(?:: ([^,\s]+))?`)
+)
+
+// ClassifyDBError 将驱动错误映射为 *AppError，原始错误作为 cause 保留 (Unwrap)
+// 唯一约束冲突 -> ErrorCodeDBDuplicate，外键 / 非空 / CHECK 约束 -> ErrorCodeDBConstraint，
+// 死锁 / 锁等待超时 / 序列化失败 / SQLite BUSY -> ErrorCodeDBDeadlock，约束名或列名记录在 Constraint
+// 链上的 *AppError (如 NewDatabaseError / Wrap 的结果) 按其 cause 中的驱动错误分类，整个 err 作为 cause 保留
+// nil、已分类的数据库错误或无法识别的错误原样返回
+func ClassifyDBError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *AppError
+	if stderrors.As(err, &appErr) && isClassifiedCode(appErr.Code) {
+		return err
+	}
+
+	kind, name := classifyDriverError(err)
+	var code ErrorCode
+	switch kind {
+	case dbErrorDuplicate:
+		code = ErrorCodeDBDuplicate
+	case dbErrorForeignKey, dbErrorNotNull, dbErrorCheck:
+		code = ErrorCodeDBConstraint
+	case dbErrorRetryable:
+		code = ErrorCodeDBDeadlock
+	default:
+		return err
+	}
+	appErr = NewError(code, err.Error()).WithCause(err)
+	appErr.Constraint = name
+	return appErr
+}
+
+// IsDuplicate 是否为唯一约束 / 主键冲突，支持原始驱动错误与 ClassifyDBError 的结果
+func IsDuplicate(err error) bool {
+	if IsErrorCode(err, ErrorCodeDBDuplicate) {
+		return true
+	}
+	kind, _ := classifyDriverError(err)
+	return kind == dbErrorDuplicate
+}
+
+// IsForeignKeyViolation 是否为外键约束冲突，支持原始驱动错误与 ErrorCodeDBConstraint 错误
+// 非空 / CHECK 约束同样映射为 ErrorCodeDBConstraint，链上能识别驱动错误时按驱动错误区分
+func IsForeignKeyViolation(err error) bool {
+	kind, _ := classifyDriverError(err)
+	if kind != dbErrorUnknown {
+		return kind == dbErrorForeignKey
+	}
+	return IsErrorCode(err, ErrorCodeDBConstraint)
+}
+
+// IsRetryable 是否为重新执行整个事务通常即可成功的冲突: 死锁、锁等待超时、序列化失败、SQLite BUSY
+func IsRetryable(err error) bool {
+	if IsErrorCode(err, ErrorCodeDBDeadlock) {
+		return true
+	}
+	kind, _ := classifyDriverError(err)
+	return kind == dbErrorRetryable
+}
+
+// isClassifiedCode 是否为 ClassifyDBError 产生的错误代码
+func isClassifiedCode(code ErrorCode) bool {
+	return code == ErrorCodeDBDuplicate || code == ErrorCodeDBConstraint || code == ErrorCodeDBDeadlock
+}
+
+// classifyDriverError 识别驱动错误类别，并提取约束名或列名
+func classifyDriverError(err error) (dbErrorKind, string) {
+	if err == nil {
+		return dbErrorUnknown, ""
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if stderrors.As(err, &mysqlErr) {
+		return classifyMySQL(mysqlErr)
+	}
+
+	// pgx (*pgconn.PgError) 提供 SQLState()，lib/pq (*pq.Error) 提供 Get('C')
+	var stateErr interface{ SQLState() string }
+	if stderrors.As(err, &stateErr) {
+		return classifyPostgres(stateErr.SQLState(), err.Error())
+	}
+	var fieldErr interface{ Get(byte) string }
+	if stderrors.As(err, &fieldErr) {
+		return classifyPostgres(fieldErr.Get('C'), err.Error())
+	}
+
+	// 仅识别 SQLite 驱动自身的错误，避免其他驱动的错误信息被误判
+	sqliteErr, ok := sqliteDriverError(err)
+	if !ok {
+		return dbErrorUnknown, ""
+	}
+	// modernc.org/sqlite 提供扩展码 Code()，mattn/go-sqlite3 只能按错误信息识别
+	if codeErr, ok := sqliteErr.(interface{ Code() int }); ok {
+		if kind, name := classifySQLiteCode(codeErr.Code(), sqliteErr.Error()); kind != dbErrorUnknown {
+			return kind, name
+		}
+	}
+	return classifySQLiteMessage(sqliteErr.Error())
+}
+
+// sqliteDriverError 错误链中来自 SQLite 驱动 (mattn/go-sqlite3、modernc.org/sqlite 等) 的错误，按类型所在包识别
+func sqliteDriverError(err error) (error, bool) {
+	for ; err != nil; err = stderrors.Unwrap(err) {
+		t := reflect.TypeOf(err)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if strings.Contains(strings.ToLower(t.PkgPath()), "sqlite") {
+			return err, true
+		}
+	}
+	return nil, false
+}
+
+// classifyMySQL 按 MySQL 错误号分类
+func classifyMySQL(err *mysql.MySQLError) (dbErrorKind, string) {
+	switch err.Number {
+	case mysqlErrDupEntry, mysqlErrDupEntryWithKeyName:
+		return dbErrorDuplicate, lastSegment(submatch(mysqlKeyPattern, err.Message))
+	case mysqlErrNoReferencedRow, mysqlErrRowIsReferenced, mysqlErrRowIsReferenced2, mysqlErrNoReferencedRow2:
+		return dbErrorForeignKey, submatch(mysqlConstraintPattern, err.Message)
+	case mysqlErrBadNull:
+		return dbErrorNotNull, submatch(mysqlColumnPattern, err.Message)
+	case mysqlErrCheckViolated:
+		return dbErrorCheck, submatch(mysqlConstraintPattern, err.Message)
+	case mysqlErrLockDeadlock, mysqlErrLockWaitTimeout:
+		return dbErrorRetryable, ""
+	}
+	return dbErrorUnknown, ""
+}
+
+// classifyPostgres 按 SQLSTATE 分类，约束名 / 列名取自错误信息
+func classifyPostgres(state, msg string) (dbErrorKind, string) {
+	switch state {
+	case pgStateUniqueViolation:
+		return dbErrorDuplicate, submatch(pgConstraintPattern, msg)
+	case pgStateForeignKeyViolation:
+		return dbErrorForeignKey, submatch(pgConstraintPattern, msg)
+	case pgStateNotNullViolation:
+		return dbErrorNotNull, submatch(pgColumnPattern, msg)
+	case pgStateCheckViolation:
+		return dbErrorCheck, submatch(pgConstraintPattern, msg)
+	case pgStateSerializationFail, pgStateDeadlockDetected:
+		return dbErrorRetryable, ""
+	}
+	return dbErrorUnknown, ""
+}
+
+// classifySQLiteCode 按 SQLite 扩展错误码分类
+func classifySQLiteCode(code int, msg string) (dbErrorKind, string) {
+	switch {
+	case code&0xff == sqliteErrBusy:
+		return dbErrorRetryable, ""
+	case code == sqliteErrConstraintUnique || code == sqliteErrConstraintPK:
+		_, name := classifySQLiteMessage(msg)
+		return dbErrorDuplicate, name
+	case code == sqliteErrConstraintFK:
+		return dbErrorForeignKey, ""
+	case code == sqliteErrConstraintNotNull:
+		_, name := classifySQLiteMessage(msg)
+		return dbErrorNotNull, name
+	case code == sqliteErrConstraintCheck:
+		_, name := classifySQLiteMessage(msg)
+		return dbErrorCheck, name
+	}
+	return dbErrorUnknown, ""
+}
+
+// classifySQLiteMessage 按 SQLite 错误信息分类，如 "UNIQUE constraint failed: users.email"
+func classifySQLiteMessage(msg string) (dbErrorKind, string) {
+	if strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY") {
+		return dbErrorRetryable, ""
+	}
+	match := sqlitePattern.FindStringSubmatch(msg)
+	if match == nil {
+		return dbErrorUnknown, ""
+	}
+	name := lastSegment(match[2])
+	switch match[1] {
+	case "UNIQUE":
+		return dbErrorDuplicate, name
+	case "NOT NULL":
+		return dbErrorNotNull, name
+	case "CHECK":
+		return dbErrorCheck, name
+	default:
+		return dbErrorForeignKey, name
+	}
+}
+
+// submatch 返回第一个捕获组，未匹配时返回空串
+func submatch(pattern *regexp.Regexp, s string) string {
+	if match := pattern.FindStringSubmatch(s); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// lastSegment 去掉表名前缀，如 users.email -> email
+func lastSegment(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
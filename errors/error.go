@@ -11,7 +11,10 @@
 
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+)
 
 // errorMessages 错误消息映射
 var errorMessages = map[ErrorCode]string{
@@ -84,9 +87,11 @@ var errorMessages = map[ErrorCode]string{
 
 // AppError 应用错误结构
 type AppError struct {
-	Code    ErrorCode // 错误代码
-	Message string    // 错误消息
-	Details string    // 错误详情
+	Code       ErrorCode // 错误代码
+	Message    string    // 错误消息
+	Details    string    // 错误详情
+	Constraint string    // 违反的约束名或列名 (数据库错误)
+	cause      error     // 原始错误
 }
 
 // BusinessError 业务错误（别名，保持兼容）
@@ -147,17 +152,31 @@ func (e *AppError) WithDetails(details string) *AppError {
 	return e
 }
 
-// IsErrorCode 检查错误代码是否匹配
+// WithCause 记录原始错误，可通过 errors.Is / errors.As 匹配
+func (e *AppError) WithCause(cause error) *AppError {
+	e.cause = cause
+	return e
+}
+
+// Unwrap 返回原始错误
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// IsErrorCode 检查错误代码是否匹配，支持被 fmt.Errorf("%w") 包装的错误
+// 按错误链取第一个 *AppError 比较，外层 AppError 的代码优先于其 cause
 func IsErrorCode(err error, code ErrorCode) bool {
-	if appErr, ok := err.(*AppError); ok {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
 		return appErr.Code == code
 	}
 	return false
 }
 
-// GetErrorCode 从错误中提取错误代码
+// GetErrorCode 从错误中提取错误代码，按错误链取第一个 *AppError
 func GetErrorCode(err error) ErrorCode {
-	if appErr, ok := err.(*AppError); ok {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
 		return appErr.Code
 	}
 	return ErrorCodeUnknown
@@ -200,7 +219,7 @@ func NewConflict(resource string) *AppError {
 
 // NewDatabaseError 创建数据库错误
 func NewDatabaseError(operation string, err error) *AppError {
-	return NewError(ErrorCodeDBError, fmt.Sprintf("database %s failed: %v", operation, err))
+	return NewError(ErrorCodeDBError, fmt.Sprintf("database %s failed: %v", operation, err)).WithCause(err)
 }
 
 // NewInternal 创建内部错误
@@ -274,5 +293,5 @@ func Wrap(err error, code ErrorCode) *AppError {
 	if err == nil {
 		return nil
 	}
-	return NewError(code, err.Error())
+	return NewError(code, err.Error()).WithCause(err)
 }
@@ -704,3 +704,14 @@ type DriverAdapterInterface interface {
 	LastInsertId(result sql.Result) (int64, error)
 	RowsAffected(result sql.Result) (int64, error)
 }
+
+// placeholderAdapter 可选接口 - 驱动适配器的方言占位符 ($n / @pN)，未实现时使用 ?
+type placeholderAdapter interface {
+	Placeholder(index int) string
+}
+
+// UpsertValidator 可选接口 - 说明 BuildUpsert 返回空语句的原因 (如缺少冲突列)
+// 内置驱动适配器均已实现，自定义适配器可按需实现
+type UpsertValidator interface {
+	ValidateUpsert(table string, data map[string]interface{}, conflictFields []string) error
+}
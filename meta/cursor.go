@@ -0,0 +1,20 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-16 17:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-16 17:00:00
+ * @FilePath: \go-sqlbuilder\meta\cursor.go
+ * @Description: 游标 (keyset) 分页元数据
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package meta
+
+// CursorPage 游标分页元数据，游标为不透明的签名字符串，可直接透传给客户端
+type CursorPage struct {
+	Limit      int32  `json:"limit"`                 // 每页记录数
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，配合 After 使用
+	PrevCursor string `json:"prev_cursor,omitempty"` // 上一页游标，配合 Before 使用
+	HasNext    bool   `json:"has_next"`              // 是否有下一页
+	HasPrev    bool   `json:"has_prev"`              // 是否有上一页
+}
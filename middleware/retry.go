@@ -12,9 +12,11 @@ package middleware
 
 import (
 	"context"
-	"errors"
+	stderrors "errors"
+	"math/rand/v2"
 	"time"
 
+	"github.com/kamalyes/go-sqlbuilder/errors"
 	"github.com/kamalyes/go-sqlbuilder/executor"
 )
 
@@ -89,6 +91,36 @@ func (b *LinearBackoff) NextBackoff(attempt int) time.Duration {
 	return time.Duration(attempt) * b.interval
 }
 
+// JitterBackoff 抖动重试等待策略，在基础策略的等待时间上随机浮动，避免并发事务同步重试再次冲突
+type JitterBackoff struct {
+	base   BackoffStrategy
+	factor float64
+}
+
+// NewJitterBackoff 创建抖动重试等待策略，factor 为浮动比例 (0, 1]，等待时间在 [d*(1-factor), d*(1+factor)) 内
+func NewJitterBackoff(base BackoffStrategy, factor float64) BackoffStrategy {
+	if base == nil {
+		base = NewDefaultBackoff()
+	}
+	if factor <= 0 || factor > 1 {
+		factor = 0.5
+	}
+	return &JitterBackoff{
+		base:   base,
+		factor: factor,
+	}
+}
+
+// NextBackoff 获取下一次重试的等待时间
+func (b *JitterBackoff) NextBackoff(attempt int) time.Duration {
+	delay := b.base.NextBackoff(attempt)
+	if delay <= 0 {
+		return 0
+	}
+	spread := float64(delay) * b.factor
+	return time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+}
+
 // DefaultRetryableChecker 默认的可重试错误检查器
 type DefaultRetryableChecker struct{}
 
@@ -104,6 +136,20 @@ func (c *DefaultRetryableChecker) IsRetryable(err error) bool {
 	return true
 }
 
+// TxRetryableChecker 事务可重试错误检查器
+// 仅死锁、锁等待超时、序列化失败与 SQLite BUSY 可重试，重新执行整个事务通常即可成功，其余错误均不重试
+type TxRetryableChecker struct{}
+
+// NewTxRetryableChecker 创建事务可重试错误检查器
+func NewTxRetryableChecker() RetryableChecker {
+	return &TxRetryableChecker{}
+}
+
+// IsRetryable 检查错误是否为可重试的事务冲突
+func (c *TxRetryableChecker) IsRetryable(err error) bool {
+	return errors.IsRetryable(err)
+}
+
 // NewRetryMiddleware 创建重试中间件
 func NewRetryMiddleware(maxAttempts int) Middleware {
 	if maxAttempts <= 0 {
@@ -248,7 +294,7 @@ func (m *CircuitBreakerRetryMiddleware) Handle(ctx context.Context, execCtx *exe
 			m.successCount = 0
 		} else {
 			// 断路器开启，直接返回错误
-			return errors.New("circuit breaker is open")
+			return stderrors.New("circuit breaker is open")
 		}
 	}
 
@@ -13,10 +13,13 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/kamalyes/go-sqlbuilder/executor"
+	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -363,3 +366,37 @@ func TestRetryMiddlewareWithExecutionContext(t *testing.T) {
 	assert.Equal(t, "SELECT * FROM users WHERE id = ?", execCtx.SQL)
 	assert.Equal(t, []interface{}{123}, execCtx.Args)
 }
+
+// pgStateError 模拟 pgx 错误
+type pgStateError struct{ state string }
+
+func (e *pgStateError) Error() string    { return "pg error " + e.state }
+func (e *pgStateError) SQLState() string { return e.state }
+
+// TestJitterBackoffNextBackoff 测试抖动退避策略
+func TestJitterBackoffNextBackoff(t *testing.T) {
+	backoff := NewJitterBackoff(NewLinearBackoff(100*time.Millisecond), 0.2)
+
+	for i := 0; i < 20; i++ {
+		d := backoff.NextBackoff(2)
+		assert.GreaterOrEqual(t, d, 160*time.Millisecond)
+		assert.Less(t, d, 240*time.Millisecond)
+	}
+	assert.Equal(t, time.Duration(0), backoff.NextBackoff(0))
+}
+
+// TestTxRetryableChecker 测试事务可重试错误识别
+func TestTxRetryableChecker(t *testing.T) {
+	checker := NewTxRetryableChecker()
+
+	assert.True(t, checker.IsRetryable(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}))
+	assert.True(t, checker.IsRetryable(fmt.Errorf("update: %w", &mysql.MySQLError{Number: 1205})))
+	assert.True(t, checker.IsRetryable(&pgStateError{state: "40001"}))
+	assert.True(t, checker.IsRetryable(&pgStateError{state: "40P01"}))
+	assert.True(t, checker.IsRetryable(sqlite3.Error{Code: sqlite3.ErrBusy}))
+
+	assert.False(t, checker.IsRetryable(nil))
+	assert.False(t, checker.IsRetryable(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}))
+	assert.False(t, checker.IsRetryable(&pgStateError{state: "23505"}))
+	assert.False(t, checker.IsRetryable(errors.New("connection refused")))
+}
@@ -0,0 +1,128 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 18:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 18:00:00
+ * @FilePath: \go-sqlbuilder\param_json_test.go
+ * @Description: JSON 过滤 DSL 测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParamJSON_Decode 测试 JSON 过滤树解析
+func TestParamJSON_Decode(t *testing.T) {
+	data := `{
+		"filter": {"and": [
+			{"field": "age", "op": "gte", "value": 18},
+			{"or": [
+				{"field": "status", "op": "in", "value": ["active", "pending"]},
+				{"field": "email", "op": "is null"}
+			]}
+		]},
+		"sort": [{"field": "created_at", "order": "desc"}],
+		"page": 2,
+		"page_size": 20
+	}`
+
+	var param query.Param
+	require.NoError(t, json.Unmarshal([]byte(data), &param))
+
+	assert.Equal(t, 2, param.Page)
+	assert.Equal(t, 20, param.PageSize)
+	require.Len(t, param.Orders, 1)
+	assert.Equal(t, query.OrderBy{Field: "created_at", Order: "DESC"}, *param.Orders[0])
+
+	sql, args := param.BuildWhereClause()
+	assert.Equal(t, "WHERE age >= ? AND (status IN (?,?) OR email IS NULL)", sql)
+	assert.Equal(t, []interface{}{int64(18), "active", "pending"}, args)
+	t.Logf("✓ JSON 过滤树解析测试通过")
+}
+
+// TestParamJSON_RoundTrip 测试 Param 序列化后再解析得到等价的 WHERE 子句
+func TestParamJSON_RoundTrip(t *testing.T) {
+	param := query.NewParam().
+		AddEQ("status", "active").
+		AddGTE("age", 18).
+		AddOrLike("name", "bob").
+		AddFilterGroup(query.NewFilterGroup("OR").
+			AddFilter("age", query.OP_BETWEEN, []interface{}{20, 30}).
+			AddFilter("email", query.OP_IS_NULL, nil)).
+		AddOrderDesc("created_at").
+		SetPage(3, 10)
+
+	data, err := json.Marshal(param)
+	require.NoError(t, err)
+
+	var decoded query.Param
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	wantSQL, _ := param.BuildWhereClause()
+	gotSQL, gotArgs := decoded.BuildWhereClause()
+	assert.Equal(t, "WHERE ((status = ? AND age >= ?) OR (name LIKE ?)) AND (age BETWEEN ? AND ? OR email IS NULL)", gotSQL)
+	assert.Contains(t, wantSQL, "status = ? AND age >= ? OR name LIKE ?")
+	assert.Equal(t, []interface{}{"active", int64(18), "%bob%", int64(20), int64(30)}, gotArgs)
+	assert.Equal(t, param.Page, decoded.Page)
+	assert.Equal(t, param.PageSize, decoded.PageSize)
+	assert.Equal(t, param.Offset, decoded.Offset)
+	assert.Equal(t, param.Orders, decoded.Orders)
+	t.Logf("✓ Param JSON 往返测试通过")
+}
+
+// TestFilterGroupJSON 测试 FilterGroup 序列化与单条件解析
+func TestFilterGroupJSON(t *testing.T) {
+	group := query.NewFilterGroup("OR").
+		AddFilter("name", query.OP_EQ, "alice").
+		AddFilter("deleted_at", query.OP_IS_NULL, nil)
+
+	data, err := json.Marshal(group)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"or":[{"field":"name","op":"=","value":"alice"},{"field":"deleted_at","op":"IS NULL"}]}`, string(data))
+
+	var single query.FilterGroup
+	require.NoError(t, json.Unmarshal([]byte(`{"field":"age","op":"LT","value":1.5}`), &single))
+	sql, args := single.BuildSQL()
+	assert.Equal(t, "(age < ?)", sql)
+	assert.Equal(t, []interface{}{1.5}, args)
+	t.Logf("✓ FilterGroup JSON 测试通过")
+}
+
+// TestParamJSON_Errors 测试非法节点、字段名、操作符与值的错误
+func TestParamJSON_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		code errors.ErrorCode
+		msg  string
+	}{
+		{"unknown operator", `{"filter":{"and":[{"field":"age","op":"~=","value":1}]}}`, errors.ErrorCodeInvalidOperator, "$.and[0]"},
+		{"bad field", `{"filter":{"or":[{"field":"age;drop","op":"eq","value":1}]}}`, errors.ErrorCodeInvalidFieldName, "$.or[0]"},
+		{"in needs array", `{"filter":{"and":[{"field":"id","op":"in","value":1}]}}`, errors.ErrorCodeInvalidFilterValue, "IN"},
+		{"between needs pair", `{"filter":{"and":[{"field":"id","op":"between","value":[1]}]}}`, errors.ErrorCodeInvalidFilterValue, "BETWEEN"},
+		{"eq needs scalar", `{"filter":{"and":[{"field":"id","op":"eq","value":{"a":1}}]}}`, errors.ErrorCodeInvalidFilterValue, "$.and[0]"},
+		{"mixed node", `{"filter":{"and":[{"or":[],"field":"id","op":"eq","value":1}]}}`, errors.ErrorCodeInvalidFilterValue, "$.and[0]"},
+		{"nested path", `{"filter":{"and":[{"or":[{"field":"id","op":"eq"}]}]}}`, errors.ErrorCodeInvalidFilterValue, "$.and[0].or[0]"},
+		{"bad sort", `{"sort":[{"field":"id","order":"sideways"}]}`, errors.ErrorCodeInvalidInput, "SIDEWAYS"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var param query.Param
+			err := json.Unmarshal([]byte(tc.data), &param)
+			require.Error(t, err)
+			assert.True(t, errors.IsErrorCode(err, tc.code), err.Error())
+			assert.Contains(t, err.Error(), tc.msg)
+		})
+	}
+	t.Logf("✓ JSON 过滤 DSL 错误测试通过")
+}
@@ -0,0 +1,123 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 16:00:00
+ * @FilePath: \go-sqlbuilder\param_url_test.go
+ * @Description: 查询字符串解析测试
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	stderrors "errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testURLSchema = &query.URLSchema{
+	Fields: map[string]query.FieldSchema{
+		"name":       {Type: query.FieldString, Sortable: true},
+		"email":      {Type: query.FieldString},
+		"age":        {Type: query.FieldInt},
+		"status":     {Type: query.FieldString, Operators: []string{query.QP_EQ}},
+		"created_at": {Type: query.FieldTime, Sortable: true},
+		"created":    {Column: "created_at", Type: query.FieldTime},
+	},
+	DefaultPageSize: 20,
+	MaxPageSize:     100,
+}
+
+// TestParseURLValues 测试查询字符串解析
+func TestParseURLValues(t *testing.T) {
+	values, err := url.ParseQuery("name__lk=foo&age__gte=18&sort=-created_at,name&page=2&status=active&status=banned")
+	require.NoError(t, err)
+
+	param, err := query.ParseURLValues(values, testURLSchema)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, param.Page)
+	assert.Equal(t, 20, param.PageSize)
+	assert.Equal(t, 20, param.Offset)
+	require.Len(t, param.Orders, 2)
+	assert.Equal(t, query.OrderBy{Field: "created_at", Order: "DESC"}, *param.Orders[0])
+	assert.Equal(t, query.OrderBy{Field: "name", Order: "ASC"}, *param.Orders[1])
+
+	sql, args := param.BuildWhereClause()
+	assert.Equal(t, "WHERE age >= ? AND name LIKE ?", sql[:len("WHERE age >= ? AND name LIKE ?")])
+	assert.Contains(t, sql, "status IN (?,?)")
+	assert.Equal(t, []interface{}{int64(18), "%foo%", "active", "banned"}, args)
+	t.Logf("✓ 查询字符串解析测试通过")
+}
+
+// TestParseURLValues_Operators 测试集合/范围/空值/OR 操作符及类型转换
+func TestParseURLValues_Operators(t *testing.T) {
+	values := url.Values{
+		"age__in":      {"1,2", "3"},
+		"created__btw": {"2025-01-01,2025-02-01T00:00:00Z"},
+		"email__null":  {"false"},
+		"name__orlk":   {"al"},
+		"email__orlk":  {"al"},
+		"name__pd":     {"1"},
+	}
+	param, err := query.ParseURLValues(values, testURLSchema)
+	require.NoError(t, err)
+
+	sql, args := param.BuildWhereClause()
+	assert.Equal(t, "WHERE age IN (?,?,?) AND created_at BETWEEN ? AND ? AND email IS NOT NULL AND (email LIKE ? OR name LIKE ?)", sql)
+	assert.Equal(t, []interface{}{
+		int64(1), int64(2), int64(3),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		"%al%", "%al%",
+	}, args)
+	assert.Equal(t, "DESC", param.Orders[0].Order)
+	t.Logf("✓ 操作符解析测试通过")
+}
+
+// TestParseURLValues_Errors 测试结构化错误
+func TestParseURLValues_Errors(t *testing.T) {
+	values := url.Values{
+		"password":     {"x"},
+		"age__gte":     {"abc"},
+		"status__lk":   {"act"},
+		"email__bogus": {"x"},
+		"sort":         {"email"},
+		"page_size":    {"500"},
+	}
+	_, err := query.ParseURLValues(values, testURLSchema)
+	require.Error(t, err)
+
+	var parseErr *query.ParseError
+	require.True(t, stderrors.As(err, &parseErr))
+
+	codes := make(map[string]errors.ErrorCode)
+	for _, field := range parseErr.Fields {
+		codes[field.Param] = field.Code
+	}
+	assert.Equal(t, map[string]errors.ErrorCode{
+		"age__gte":     errors.ErrorCodeInvalidFilterValue,
+		"email__bogus": errors.ErrorCodeInvalidOperator,
+		"page_size":    errors.ErrorCodePageSizeInvalid,
+		"password":     errors.ErrorCodeInvalidFieldName,
+		"sort":         errors.ErrorCodeInvalidFieldName,
+		"status__lk":   errors.ErrorCodeInvalidOperator,
+	}, codes)
+
+	var appErr *errors.AppError
+	assert.True(t, stderrors.As(err, &appErr))
+
+	// 忽略未定义参数
+	schema := *testURLSchema
+	schema.IgnoreUnknown = true
+	param, err := query.ParseURLValues(url.Values{"token": {"x"}, "age": {"3"}}, &schema)
+	require.NoError(t, err)
+	assert.Len(t, param.Filters, 1)
+	t.Logf("✓ 结构化错误测试通过")
+}
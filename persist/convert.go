@@ -0,0 +1,118 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 10:00:00
+ * @FilePath: \go-sqlbuilder\persist\convert.go
+ * @Description: Filter / Order 与规范的 query.Param 互转
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package persist
+
+import (
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/query"
+	"gorm.io/gorm"
+)
+
+// paramFilter 可转换为 query.Param 条件的过滤器
+type paramFilter interface {
+	toParam(param *query.Param)
+}
+
+// ToParam 将过滤器与排序转换为 query.Param (不分页)
+// OriginalWhereFilter / OriginalOrFilter / JSONArrayContainsFilter 等原生或方言相关的过滤器无法转换，
+// 返回 ErrorCodeUnsupported
+func ToParam(filters Filters, orders ...Order) (*query.Param, error) {
+	param := query.NewParam()
+	param.PageSize = 0
+	for _, filter := range filters {
+		f, ok := filter.(paramFilter)
+		if !ok {
+			return nil, errors.NewErrorf(errors.ErrorCodeUnsupported, errors.MsgFilterNotConvertible, filter)
+		}
+		f.toParam(param)
+	}
+	for _, order := range orders {
+		fieldOrder, ok := order.(*FieldOrder)
+		if !ok {
+			return nil, errors.NewErrorf(errors.ErrorCodeUnsupported, errors.MsgFilterNotConvertible, order)
+		}
+		if fieldOrder.Reversed {
+			param.AddOrderDesc(fieldOrder.Field)
+		} else {
+			param.AddOrderAsc(fieldOrder.Field)
+		}
+	}
+	return param, nil
+}
+
+// ParamFilter 以 query.Param 的过滤条件作为 Filter，可直接用于 List / GetByFilters
+type ParamFilter struct {
+	Param *query.Param
+}
+
+func (pf *ParamFilter) Where(db *gorm.DB) *gorm.DB {
+	if pf.Param == nil {
+		return db
+	}
+	return pf.Param.GormFilterScope()(db)
+}
+
+func NewParamFilter(param *query.Param) Filter {
+	return &ParamFilter{Param: param}
+}
+
+func (eq *EqFilter) toParam(param *query.Param) {
+	param.AddEQ(eq.Name, eq.Value)
+}
+
+func (ne *NeFilter) toParam(param *query.Param) {
+	param.AddNEQ(ne.Name, ne.Value)
+}
+
+func (or *OrFilter[T]) toParam(param *query.Param) {
+	if len(or.Values) < 1 {
+		return
+	}
+	param.AddOrFilter(or.Name, query.OP_IN, or.Values)
+}
+
+func (in *InFilter[T]) toParam(param *query.Param) {
+	param.AddFilter(in.Name, query.OP_IN, in.Values)
+}
+
+func (f *LikeFilter) toParam(param *query.Param) {
+	param.AddLike(f.Name, f.Value)
+}
+
+func (p *PrefixFilter) toParam(param *query.Param) {
+	param.AddStartsWith(p.Name, p.Value)
+}
+
+func (s *SuffixFilter) toParam(param *query.Param) {
+	param.AddEndsWith(s.Name, s.Value)
+}
+
+func (p *PeriodFilter) toParam(param *query.Param) {
+	if p.End.IsZero() {
+		return
+	}
+	if p.Start.IsZero() {
+		param.AddLT(p.Name, p.End)
+		return
+	}
+	param.AddFilter(p.Name, query.OP_BETWEEN, []interface{}{p.Start, p.End})
+}
+
+func (r RangeFilter[T]) toParam(param *query.Param) {
+	if r.End == 0 {
+		return
+	}
+	if r.Start == 0 {
+		param.AddLT(r.Name, r.End)
+		return
+	}
+	param.AddGTE(r.Name, r.Start).AddLT(r.Name, r.End)
+}
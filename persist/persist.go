@@ -16,6 +16,7 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/kamalyes/go-sqlbuilder/meta"
+	"github.com/kamalyes/go-sqlbuilder/txctx"
 	"gorm.io/gorm"
 )
 
@@ -24,16 +25,38 @@ type Model interface {
 	CacheTTL() time.Duration
 }
 
+// session 返回绑定 ctx 的会话，ctx 中存在同一数据库的 txctx 事务时加入该事务
+// 事务属于其他数据库时会话携带错误
+func session(ctx context.Context, tx DBHandler) *gorm.DB {
+	return txctx.DB(ctx, tx.DB())
+}
+
+// queryDB 按查询参数构建查询，ctx 中存在同一数据库的 txctx 事务时在该事务上执行
+// 查询始终经由 DBHandler.Query 构建，事务内只替换连接，保留 handler 的作用域与表路由
+func queryDB(ctx context.Context, tx DBHandler, param *QueryParam) *gorm.DB {
+	query := tx.Query(param).WithContext(ctx)
+	txDB, ok, err := txctx.Join(ctx, tx.DB())
+	if err != nil {
+		_ = query.AddError(err)
+		return query
+	}
+	if ok {
+		// WithContext 已复制 Statement，替换连接不影响 handler 的原始会话
+		query.Statement.ConnPool = txDB.Statement.ConnPool
+	}
+	return query
+}
+
 func Create[T any](ctx context.Context, tx DBHandler, tCreates ...*T) (err error) {
 	if len(tCreates) == 0 {
 		return nil
 	}
-	err = tx.DB().WithContext(ctx).Create(&tCreates).Error
+	err = session(ctx, tx).Create(&tCreates).Error
 	return
 }
 
 func Get[T Model](ctx context.Context, tx DBHandler, t *T) (tGet *T, err error) {
-	err = tx.DB().WithContext(ctx).Where(t).First(&tGet).Error
+	err = session(ctx, tx).Where(t).First(&tGet).Error
 	return
 }
 
@@ -44,7 +67,7 @@ func GetByFilters[T Model](ctx context.Context, tx DBHandler, filters Filters, o
 	}
 
 	param := NewQueryParam(filters, nil, opts...)
-	err = tx.Query(param).WithContext(ctx).First(&tGet).Error
+	err = queryDB(ctx, tx, param).First(&tGet).Error
 	if err != nil {
 		return
 	}
@@ -58,13 +81,13 @@ func List[T Model](ctx context.Context, tx DBHandler, filters Filters, page *met
 	}
 
 	param := NewQueryParam(filters, page, opts...)
-	err = tx.Query(param).Model(new(T)).WithContext(ctx).Find(&tList).Error
+	err = queryDB(ctx, tx, param).Model(new(T)).Find(&tList).Error
 	if err != nil {
 		return
 	}
 	if page != nil {
 		param = NewQueryParam(filters, nil)
-		err = tx.Query(param).Model(new(T)).WithContext(ctx).Count(&page.Total).Error
+		err = queryDB(ctx, tx, param).Model(new(T)).Count(&page.Total).Error
 	}
 	return
 }
@@ -75,7 +98,7 @@ func Update[T Model](ctx context.Context, tx DBHandler, tUpdates ...*T) (err err
 	}
 
 	// 使用事务确保批量更新的一致性
-	err = tx.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = session(ctx, tx).Transaction(func(tx *gorm.DB) error {
 		for _, item := range tUpdates {
 			if err := tx.Updates(item).Error; err != nil {
 				return err
@@ -91,7 +114,7 @@ func Save[T Model](ctx context.Context, tx DBHandler, tSaves ...*T) (err error)
 		return nil
 	}
 
-	err = tx.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = session(ctx, tx).Transaction(func(tx *gorm.DB) error {
 		for _, item := range tSaves {
 			if err := tx.Save(item).Error; err != nil {
 				return err
@@ -107,7 +130,7 @@ func Delete[T Model](ctx context.Context, tx DBHandler, tDeletes ...*T) (err err
 		return nil
 	}
 
-	err = tx.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err = session(ctx, tx).Transaction(func(tx *gorm.DB) error {
 		for _, item := range tDeletes {
 			if err := tx.Delete(item).Error; err != nil {
 				return err
@@ -11,6 +11,8 @@
 
 package query
 
+import "strings"
+
 // Filter 单个过滤条件
 type Filter struct {
 	Field    string      // 字段名
@@ -20,6 +22,7 @@ type Filter struct {
 }
 
 // FilterGroup 过滤组（支持嵌套）
+// 组内的 Filters 与 Groups 均以组的 Logic 连接，整体作为一个带括号的条件
 type FilterGroup struct {
 	Filters []*Filter
 	Groups  []*FilterGroup
@@ -44,6 +47,83 @@ func NewFilter(field string, operator Operator, value interface{}) *Filter {
 	}
 }
 
+// NewFilterGroup 创建过滤组，logic 为空时默认 AND
+func NewFilterGroup(logic string) *FilterGroup {
+	if logic == "" {
+		logic = "AND"
+	}
+	return &FilterGroup{
+		Filters: make([]*Filter, 0),
+		Groups:  make([]*FilterGroup, 0),
+		Logic:   strings.ToUpper(logic),
+	}
+}
+
+// AddFilter 向组内添加过滤条件
+func (g *FilterGroup) AddFilter(field string, operator Operator, value interface{}) *FilterGroup {
+	g.Filters = append(g.Filters, NewFilter(field, operator, value))
+	return g
+}
+
+// AddGroup 向组内添加嵌套组
+func (g *FilterGroup) AddGroup(group *FilterGroup) *FilterGroup {
+	g.Groups = append(g.Groups, group)
+	return g
+}
+
+// Connector 组内条件的连接符 (AND / OR)
+func (g *FilterGroup) Connector() string {
+	if strings.EqualFold(g.Logic, "OR") {
+		return "OR"
+	}
+	return "AND"
+}
+
+// IsEmpty 组内(含嵌套组)是否没有任何条件
+func (g *FilterGroup) IsEmpty() bool {
+	if g == nil {
+		return true
+	}
+	if len(g.Filters) > 0 {
+		return false
+	}
+	for _, sub := range g.Groups {
+		if !sub.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildSQL 构建带括号的组条件 SQL 及参数，空组返回空字符串
+func (g *FilterGroup) BuildSQL() (string, []interface{}) {
+	return g.buildSQL(nil)
+}
+
+func (g *FilterGroup) buildSQL(r *renderer) (string, []interface{}) {
+	if g.IsEmpty() {
+		return "", nil
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, filter := range g.Filters {
+		sql, filterArgs := buildFilterSQL(filter, r)
+		parts = append(parts, sql)
+		args = append(args, filterArgs...)
+	}
+	for _, sub := range g.Groups {
+		sql, subArgs := sub.buildSQL(r)
+		if sql == "" {
+			continue
+		}
+		parts = append(parts, sql)
+		args = append(args, subArgs...)
+	}
+
+	return "(" + strings.Join(parts, " "+g.Connector()+" ") + ")", args
+}
+
 // NewBaseInfoFilter 创建基础过滤信息
 func NewBaseInfoFilter(field string, values []interface{}) *BaseInfoFilter {
 	return &BaseInfoFilter{
@@ -0,0 +1,74 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 10:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 10:00:00
+ * @FilePath: \go-sqlbuilder\query\gorm.go
+ * @Description: GORM 适配 - 将 Param 作为 gorm Scope 应用
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package query
+
+import "gorm.io/gorm"
+
+// GormScope 返回应用过滤条件、HAVING、排序、去重、查询字段与分页的 gorm Scope
+// 字段名、操作符与排序方向先按 Validate 校验，失败时错误记录到 db.Error；标识符按 gorm 方言引用
+// 分页按 Pagination() 追加 LIMIT / OFFSET: NewParam() 默认 PageSize 为 10，不需要分页时将 PageSize 置 0
+// 或使用 GormFilterScope
+//
+//	db.Model(&User{}).Scopes(param.GormScope()).Find(&users)
+func (p *Param) GormScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = p.GormFilterScope()(db)
+		if db.Error != nil {
+			return db
+		}
+
+		if len(p.SelectFields) > 0 {
+			db = db.Select(p.SelectFields)
+		}
+		if p.Distinct {
+			db = db.Distinct()
+		}
+		for _, clause := range p.HavingClauses {
+			db = db.Having(clause)
+		}
+		r := gormRenderer(db)
+		for _, order := range p.Orders {
+			direction, err := order.Direction()
+			if err != nil {
+				_ = db.AddError(err)
+				return db
+			}
+			db = db.Order(r.ident(order.Field) + " " + direction)
+		}
+		if limit, offset := p.Pagination(); limit > 0 {
+			db = db.Limit(limit).Offset(offset)
+		}
+		return db
+	}
+}
+
+// GormFilterScope 只应用过滤条件的 gorm Scope，适用于 Count 等不需要排序和分页的查询
+func (p *Param) GormFilterScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if err := p.Validate(nil); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		sql, args := p.buildConditions(gormRenderer(db))
+		if sql == "" {
+			return db
+		}
+		return db.Where(sql, args...)
+	}
+}
+
+// gormRenderer 使用 gorm 方言引用标识符的渲染器
+func gormRenderer(db *gorm.DB) *renderer {
+	return &renderer{quote: func(name string) string {
+		return db.Statement.Quote(name)
+	}}
+}
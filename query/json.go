@@ -0,0 +1,299 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 18:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 18:00:00
+ * @FilePath: \go-sqlbuilder\query\json.go
+ * @Description: JSON 过滤 DSL - Param / FilterGroup 的序列化与反序列化
+ *
+ * 过滤树节点为条件或组，二者互斥:
+ *
+ *	条件: {"field": "age", "op": "gte", "value": 18}
+ *	组:   {"and": [节点, ...]} 或 {"or": [节点, ...]}
+ *
+ * op 取 constant.OperatorMap (=, !=, LIKE, IN, BETWEEN, IS NULL ...) 或
+ * constant.CompatOperatorMap (eq, neq, gt, gte, lt, lte, lk)，不区分大小写；
+ * IN / NOT IN 的 value 为数组，BETWEEN 为 [min, max]，IS [NOT] NULL 无 value
+ *
+ * Param:
+ *
+ *	{
+ *	  "filter":    {"and": [...]},
+ *	  "sort":      [{"field": "created_at", "order": "DESC"}],
+ *	  "page":      2,
+ *	  "page_size": 20,
+ *	  "offset":    0,
+ *	  "limit":     0,
+ *	  "distinct":  false,
+ *	  "select":    ["id", "name"]
+ *	}
+ *
+ * HavingClauses 为原生 SQL 片段，不参与序列化
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// filterNode 过滤树节点的 JSON 形式
+type filterNode struct {
+	And   []json.RawMessage `json:"and,omitempty"`
+	Or    []json.RawMessage `json:"or,omitempty"`
+	Field string            `json:"field,omitempty"`
+	Op    string            `json:"op,omitempty"`
+	Value json.RawMessage   `json:"value,omitempty"`
+}
+
+// orderJSON 排序的 JSON 形式
+type orderJSON struct {
+	Field string `json:"field"`
+	Order string `json:"order,omitempty"`
+}
+
+// paramJSON Param 的 JSON 形式
+type paramJSON struct {
+	Filter   *FilterGroup `json:"filter,omitempty"`
+	Sort     []orderJSON  `json:"sort,omitempty"`
+	Page     int          `json:"page,omitempty"`
+	PageSize int          `json:"page_size,omitempty"`
+	Offset   int          `json:"offset,omitempty"`
+	Limit    int          `json:"limit,omitempty"`
+	Distinct bool         `json:"distinct,omitempty"`
+	Select   []string     `json:"select,omitempty"`
+}
+
+// ==================== FilterGroup ====================
+
+// MarshalJSON 序列化为 {"and": [...]} / {"or": [...]}
+func (g *FilterGroup) MarshalJSON() ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(g.Filters)+len(g.Groups))
+	for _, filter := range g.Filters {
+		data, err := marshalFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+	for _, sub := range g.Groups {
+		data, err := sub.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data)
+	}
+
+	key := strings.ToLower(g.Connector())
+	return json.Marshal(map[string][]json.RawMessage{key: items})
+}
+
+// UnmarshalJSON 解析过滤树并校验字段名与操作符，单个条件视为只含该条件的 AND 组
+func (g *FilterGroup) UnmarshalJSON(data []byte) error {
+	group, filter, err := decodeFilterNode(data, "$")
+	if err != nil {
+		return err
+	}
+	if filter != nil {
+		group = NewFilterGroup(string(constant.LOGIC_AND))
+		group.Filters = append(group.Filters, filter)
+	}
+	*g = *group
+	return nil
+}
+
+// ==================== Param ====================
+
+// MarshalJSON 序列化为过滤树、排序、分页与查询字段
+// Filters 中的 OR 链按 AND 优先于 OR 拆分为组，TimeRanges / FindInSets 转为 BETWEEN / FIND_IN_SET 条件
+func (p *Param) MarshalJSON() ([]byte, error) {
+	out := paramJSON{
+		Page:     p.Page,
+		PageSize: p.PageSize,
+		Offset:   p.Offset,
+		Limit:    p.Limit,
+		Distinct: p.Distinct,
+		Select:   p.SelectFields,
+	}
+	if tree := p.FilterTree(); !tree.IsEmpty() {
+		out.Filter = tree
+	}
+	for _, order := range p.Orders {
+		out.Sort = append(out.Sort, orderJSON{Field: order.Field, Order: order.Order})
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 解析并按 Validate 校验字段名、操作符与排序方向，未出现的分页字段取 NewParam 的默认值
+// 根节点为 AND 组时，其条件与子组分别还原到 Filters / FilterGroups
+func (p *Param) UnmarshalJSON(data []byte) error {
+	var in paramJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	param := NewParam()
+	if in.Filter != nil {
+		if in.Filter.Connector() == string(constant.LOGIC_AND) {
+			param.Filters = append(param.Filters, in.Filter.Filters...)
+			param.FilterGroups = append(param.FilterGroups, in.Filter.Groups...)
+		} else {
+			param.FilterGroups = append(param.FilterGroups, in.Filter)
+		}
+	}
+	for _, order := range in.Sort {
+		param.AddOrder(order.Field, order.Order)
+	}
+	if in.Page > 0 {
+		param.Page = in.Page
+	}
+	if in.PageSize > 0 {
+		param.PageSize = in.PageSize
+	}
+	param.Offset = in.Offset
+	param.Limit = in.Limit
+	param.Distinct = in.Distinct
+	if in.Select != nil {
+		param.SelectFields = in.Select
+	}
+
+	if err := param.Validate(nil); err != nil {
+		return err
+	}
+	*p = *param
+	return nil
+}
+
+// ==================== 编解码 ====================
+
+func marshalFilter(filter *Filter) ([]byte, error) {
+	node := map[string]interface{}{"field": filter.Field, "op": string(filter.Operator)}
+	switch constant.Operator(strings.ToUpper(string(filter.Operator))) {
+	case constant.OP_IS_NULL, constant.OP_IS_NOT_NULL:
+	default:
+		node["value"] = filter.Value
+	}
+	return json.Marshal(node)
+}
+
+// decodeFilterNode 解析节点，返回组或条件之一
+func decodeFilterNode(data []byte, path string) (*FilterGroup, *Filter, error) {
+	var node filterNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, nil, err
+	}
+
+	kinds := 0
+	if node.And != nil {
+		kinds++
+	}
+	if node.Or != nil {
+		kinds++
+	}
+	if node.Field != "" || node.Op != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		return nil, nil, errors.NewErrorf(errors.ErrorCodeInvalidFilterValue, errors.MsgFilterNodeInvalid, path)
+	}
+
+	if node.Field == "" && node.Op == "" {
+		logic, items := constant.LOGIC_AND, node.And
+		if node.Or != nil {
+			logic, items = constant.LOGIC_OR, node.Or
+		}
+		group := NewFilterGroup(string(logic))
+		for i, item := range items {
+			itemPath := fmt.Sprintf("%s.%s[%d]", path, strings.ToLower(string(logic)), i)
+			sub, filter, err := decodeFilterNode(item, itemPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			if filter != nil {
+				group.Filters = append(group.Filters, filter)
+			} else {
+				group.Groups = append(group.Groups, sub)
+			}
+		}
+		return group, nil, nil
+	}
+
+	filter, err := decodeFilter(&node, path)
+	return nil, filter, err
+}
+
+// decodeFilter 校验字段名、操作符与值的形态
+func decodeFilter(node *filterNode, path string) (*Filter, error) {
+	if !column.IdentifierPattern.MatchString(node.Field) {
+		return nil, errors.NewErrorf(errors.ErrorCodeInvalidFieldName, errors.MsgFilterFieldInvalid, path, node.Field)
+	}
+	op, err := column.ParseOperator(node.Op)
+	if err != nil {
+		return nil, errors.NewErrorf(errors.ErrorCodeInvalidOperator, errors.MsgFilterOperatorInvalid, path, node.Op)
+	}
+
+	var value interface{}
+	if len(node.Value) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(node.Value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		value = normalizeJSONValue(value)
+	}
+
+	invalid := errors.NewErrorf(errors.ErrorCodeInvalidFilterValue, errors.MsgFilterValueInvalid, path, op)
+	if _, isObject := value.(map[string]interface{}); isObject {
+		return nil, invalid
+	}
+	values, isArray := value.([]interface{})
+	switch op {
+	case constant.OP_IN, constant.OP_NOT_IN:
+		if !isArray {
+			return nil, invalid
+		}
+	case constant.OP_BETWEEN:
+		if !isArray || len(values) != 2 {
+			return nil, invalid
+		}
+	case constant.OP_IS_NULL, constant.OP_IS_NOT_NULL:
+		if value != nil {
+			return nil, invalid
+		}
+	default:
+		if value == nil || isArray {
+			return nil, invalid
+		}
+	}
+	return NewFilter(node.Field, Operator(op), value), nil
+}
+
+// normalizeJSONValue json.Number 转为 int64 / float64
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeJSONValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -11,6 +11,13 @@
 
 package query
 
+import (
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
 // PageBean 分页响应 - 借鉴 go-core 设计
 type PageBean struct {
 	Page     int         `json:"page"`      // 当前页码
@@ -44,6 +51,19 @@ type OrderBy struct {
 	Order string // ASC / DESC
 }
 
+// Direction 规范化排序方向: 空值为 ASC，大小写不敏感，其余值返回错误
+// Builder.ApplyParam、GormScope 与 Validate 共用同一规则
+func (o *OrderBy) Direction() (string, error) {
+	switch {
+	case o.Order == "", strings.EqualFold(o.Order, constant.OrderASC):
+		return constant.OrderASC, nil
+	case strings.EqualFold(o.Order, constant.OrderDESC):
+		return constant.OrderDESC, nil
+	default:
+		return "", errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgInvalidSortOrder, o.Order)
+	}
+}
+
 // NewOrderBy 创建排序条件
 func NewOrderBy(field string, order string) *OrderBy {
 	if order == "" {
@@ -13,9 +13,11 @@ package query
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	logger "github.com/kamalyes/go-logger"
+	"github.com/kamalyes/go-sqlbuilder/column"
 	"github.com/kamalyes/go-sqlbuilder/constant"
 )
 
@@ -71,6 +73,20 @@ func (p *Param) AddOrFilter(field string, operator Operator, value interface{})
 	return p.AddFilter(field, operator, value)
 }
 
+// AddCondition 添加类型安全的列条件，如 p.AddCondition(Users.Email.Eq("a@b.com"))
+func (p *Param) AddCondition(conds ...column.Condition) *Param {
+	for _, cond := range conds {
+		p.AddFilter(cond.Field, Operator(cond.Operator), cond.Value)
+	}
+	return p
+}
+
+// AddFilterGroup 添加过滤组，与其他条件以 AND 连接
+func (p *Param) AddFilterGroup(group *FilterGroup) *Param {
+	p.FilterGroups = append(p.FilterGroups, group)
+	return p
+}
+
 // ==================== 便捷方法 ====================
 
 // AddEQ 添加等于过滤
@@ -234,73 +250,203 @@ func (p *Param) AddHaving(clause string) *Param {
 	return p
 }
 
+// Pagination 返回 LIMIT / OFFSET: 设置了 Limit 时取 Limit / Offset，否则按 Page / PageSize 计算
+// PageSize 不大于 0 时 limit 为 0，表示不分页
+func (p *Param) Pagination() (limit, offset int) {
+	if p.Limit > 0 {
+		return p.Limit, p.Offset
+	}
+	if p.PageSize <= 0 {
+		return 0, p.Offset
+	}
+	if p.Offset > 0 || p.Page <= 1 {
+		return p.PageSize, p.Offset
+	}
+	return p.PageSize, (p.Page - 1) * p.PageSize
+}
+
+// ==================== 过滤树 ====================
+
+// FilterTree 将 Filters / FilterGroups / TimeRanges / FindInSets 合并为一棵以 AND 为根的过滤树
+// 过滤树是各执行端 (Builder、GORM、JSON) 共用的规范形式，与 BuildWhereClause 语义一致
+func (p *Param) FilterTree() *FilterGroup {
+	root := NewFilterGroup(string(constant.LOGIC_AND))
+
+	// Filters 以前一个条件的 Logic 连接，AND 优先于 OR
+	var segments [][]*Filter
+	var current []*Filter
+	for _, filter := range p.Filters {
+		current = append(current, filter)
+		if strings.EqualFold(filter.Logic, string(constant.LOGIC_OR)) {
+			segments = append(segments, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+	switch {
+	case len(segments) == 1:
+		root.Filters = append(root.Filters, segments[0]...)
+	case len(segments) > 1:
+		or := NewFilterGroup(string(constant.LOGIC_OR))
+		if len(segments) == len(p.Filters) {
+			// 纯 OR 链: a OR b OR c
+			or.Filters = append(or.Filters, p.Filters...)
+		} else {
+			for _, segment := range segments {
+				and := NewFilterGroup(string(constant.LOGIC_AND))
+				and.Filters = segment
+				or.AddGroup(and)
+			}
+		}
+		root.AddGroup(or)
+	}
+
+	root.Groups = append(root.Groups, p.FilterGroups...)
+
+	for _, field := range sortedKeys(p.TimeRanges) {
+		timeRange := p.TimeRanges[field]
+		root.AddFilter(field, OP_BETWEEN, []interface{}{timeRange[0], timeRange[1]})
+	}
+	for _, field := range sortedKeys(p.FindInSets) {
+		for _, value := range p.FindInSets[field] {
+			root.AddFilter(field, OP_FIND_IN_SET, value)
+		}
+	}
+	return root
+}
+
 // ==================== WHERE 子句构建 ====================
 
 // BuildWhereClause 构建 WHERE 子句 - 返回 WHERE SQL 片段和参数
 func (p *Param) BuildWhereClause() (string, []interface{}) {
+	return p.buildWhereClause(nil)
+}
+
+// buildWhereClause 按渲染器构建 WHERE 子句，r 为 nil 时字段名与操作符原样输出
+func (p *Param) buildWhereClause(r *renderer) (string, []interface{}) {
+	sql, args := p.buildConditions(r)
+	if sql == "" {
+		logger.Debug("query.Param: no where clauses built")
+		return "", args
+	}
+	return "WHERE " + sql, args
+}
+
+// buildConditions 构建以 AND 连接的条件表达式 (不含 WHERE 关键字)
+func (p *Param) buildConditions(r *renderer) (string, []interface{}) {
 	var whereClauses []string
 	var args []interface{}
 
-	// 处理普通过滤
-	for _, filter := range p.Filters {
-		sql, filterArgs := p.buildFilterSQL(filter)
+	// 处理普通过滤 (Filter.Logic 为与下一个条件的连接符)
+	if len(p.Filters) > 0 {
+		var filterSQL strings.Builder
+		hasOr := false
+		for i, filter := range p.Filters {
+			sql, filterArgs := buildFilterSQL(filter, r)
+			if i > 0 {
+				connector := string(constant.LOGIC_AND)
+				if strings.EqualFold(p.Filters[i-1].Logic, string(constant.LOGIC_OR)) {
+					connector = string(constant.LOGIC_OR)
+					hasOr = true
+				}
+				filterSQL.WriteString(" " + connector + " ")
+			}
+			filterSQL.WriteString(sql)
+			args = append(args, filterArgs...)
+		}
+		if hasOr {
+			whereClauses = append(whereClauses, "("+filterSQL.String()+")")
+		} else {
+			whereClauses = append(whereClauses, filterSQL.String())
+		}
+	}
+
+	// 处理过滤组 (支持嵌套)
+	for _, group := range p.FilterGroups {
+		sql, groupArgs := group.buildSQL(r)
+		if sql == "" {
+			continue
+		}
 		whereClauses = append(whereClauses, sql)
-		args = append(args, filterArgs...)
+		args = append(args, groupArgs...)
 	}
 
-	// 处理时间范围
-	for field, timeRange := range p.TimeRanges {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s BETWEEN ? AND ?", field))
+	// 处理时间范围 (按字段名排序，保证 SQL 与参数顺序稳定)
+	for _, field := range sortedKeys(p.TimeRanges) {
+		timeRange := p.TimeRanges[field]
+		whereClauses = append(whereClauses, fmt.Sprintf("%s BETWEEN ? AND ?", r.ident(field)))
 		args = append(args, timeRange[0], timeRange[1])
 	}
 
 	// 处理 FIND_IN_SET
-	for field, values := range p.FindInSets {
-		for _, value := range values {
-			whereClauses = append(whereClauses, fmt.Sprintf("FIND_IN_SET(?, %s) > 0", field))
+	for _, field := range sortedKeys(p.FindInSets) {
+		for _, value := range p.FindInSets[field] {
+			whereClauses = append(whereClauses, fmt.Sprintf("FIND_IN_SET(?, %s) > 0", r.ident(field)))
 			args = append(args, value)
 		}
 	}
 
-	if len(whereClauses) == 0 {
-		logger.Debug("query.Param: no where clauses built")
-		return "", args
-	}
-
-	return "WHERE " + strings.Join(whereClauses, " AND "), args
+	return strings.Join(whereClauses, " AND "), args
 }
 
 // buildFilterSQL 构建单个过滤 SQL
-func (p *Param) buildFilterSQL(filter *Filter) (string, []interface{}) {
+// 空 IN 渲染为 1 = 0、空 NOT IN 渲染为 1 = 1；BETWEEN 的值不是两个时渲染为 1 = 0，错误由 Validate 返回
+func buildFilterSQL(filter *Filter, r *renderer) (string, []interface{}) {
 	var args []interface{}
 	var sql string
 
-	switch filter.Operator {
-	case OP_IN:
-		values := filter.Value.([]interface{})
+	field := r.ident(filter.Field)
+	switch operator := r.operator(filter.Operator); operator {
+	case OP_IN, Operator(constant.OP_NOT_IN):
+		values := column.Values(filter.Value)
+		if len(values) == 0 {
+			if operator == OP_IN {
+				return "1 = 0", nil
+			}
+			return "1 = 1", nil
+		}
 		placeholders := make([]string, len(values))
 		for i, v := range values {
 			placeholders[i] = "?"
 			args = append(args, v)
 		}
-		sql = fmt.Sprintf("%s IN (%s)", filter.Field, strings.Join(placeholders, ","))
+		sql = fmt.Sprintf("%s %s (%s)", field, operator, strings.Join(placeholders, ","))
 
 	case OP_LIKE:
-		sql = fmt.Sprintf("%s LIKE ?", filter.Field)
+		sql = fmt.Sprintf("%s LIKE ?", field)
 		args = append(args, filter.Value)
 
 	case OP_BETWEEN:
-		values := filter.Value.([2]interface{})
-		sql = fmt.Sprintf("%s BETWEEN ? AND ?", filter.Field)
+		values := column.Values(filter.Value)
+		if len(values) != 2 {
+			return "1 = 0", nil
+		}
+		sql = fmt.Sprintf("%s BETWEEN ? AND ?", field)
 		args = append(args, values[0], values[1])
 
-	case OP_IS_NULL:
-		sql = fmt.Sprintf("%s IS NULL", filter.Field)
+	case OP_IS_NULL, Operator(constant.OP_IS_NOT_NULL):
+		sql = fmt.Sprintf("%s %s", field, operator)
+
+	case OP_FIND_IN_SET:
+		sql = fmt.Sprintf("FIND_IN_SET(?, %s) > 0", field)
+		args = append(args, filter.Value)
 
 	default:
-		sql = fmt.Sprintf("%s %s ?", filter.Field, filter.Operator)
+		sql = fmt.Sprintf("%s %s ?", field, operator)
 		args = append(args, filter.Value)
 	}
 
 	return sql, args
 }
+
+// sortedKeys 按字典序返回 map 的键，保证输出稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 14:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 14:00:00
+ * @FilePath: \go-sqlbuilder\query\safe.go
+ * @Description: 安全模式 - 校验字段名/操作符并引用标识符
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package query
+
+import (
+	"strings"
+
+	"github.com/kamalyes/go-sqlbuilder/column"
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// renderer 安全模式下的 SQL 渲染: 引用字段名并规范化操作符，nil 时原样输出
+type renderer struct {
+	quote func(string) string
+}
+
+func (r *renderer) ident(name string) string {
+	if r == nil || r.quote == nil {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = r.quote(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func (r *renderer) operator(op Operator) Operator {
+	if r == nil {
+		return op
+	}
+	if parsed, err := column.ParseOperator(string(op)); err == nil {
+		return Operator(parsed)
+	}
+	return op
+}
+
+// Validate 校验过滤条件、过滤组、时间范围、FIND_IN_SET、排序与查询字段中的字段名及操作符
+// validator 为 nil 时按 column.IdentifierPattern 校验；HavingClauses 为原生片段，不做校验
+func (p *Param) Validate(validator *column.Validator) error {
+	for _, filter := range p.Filters {
+		if err := validateFilter(filter, validator); err != nil {
+			return err
+		}
+	}
+	for _, group := range p.FilterGroups {
+		if err := group.Validate(validator); err != nil {
+			return err
+		}
+	}
+	for field := range p.TimeRanges {
+		if err := validator.ValidateName(field); err != nil {
+			return err
+		}
+	}
+	for field := range p.FindInSets {
+		if err := validator.ValidateName(field); err != nil {
+			return err
+		}
+	}
+	for _, order := range p.Orders {
+		if err := validator.ValidateName(order.Field); err != nil {
+			return err
+		}
+		if _, err := order.Direction(); err != nil {
+			return err
+		}
+	}
+	for _, field := range p.SelectFields {
+		if err := validator.ValidateName(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildWhereClauseSafe 校验后构建 WHERE 子句
+// quote 为方言的标识符引用函数 (如 DriverAdapterInterface.QuoteIdentifier)，为 nil 时不引用
+// 兼容操作符 (eq / gt / lk ...) 规范化为标准操作符
+func (p *Param) BuildWhereClauseSafe(validator *column.Validator, quote func(string) string) (string, []interface{}, error) {
+	if err := p.Validate(validator); err != nil {
+		return "", nil, err
+	}
+	sql, args := p.buildWhereClause(&renderer{quote: quote})
+	return sql, args, nil
+}
+
+// Validate 递归校验组内字段名及操作符
+func (g *FilterGroup) Validate(validator *column.Validator) error {
+	if g == nil {
+		return nil
+	}
+	for _, filter := range g.Filters {
+		if err := validateFilter(filter, validator); err != nil {
+			return err
+		}
+	}
+	for _, sub := range g.Groups {
+		if err := sub.Validate(validator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFilter(filter *Filter, validator *column.Validator) error {
+	if err := validator.ValidateName(filter.Field); err != nil {
+		return err
+	}
+	operator, err := column.ParseOperator(string(filter.Operator))
+	if err != nil {
+		return err
+	}
+	if operator == constant.OP_BETWEEN {
+		if values := column.Values(filter.Value); len(values) != 2 {
+			return errors.NewErrorf(errors.ErrorCodeInvalidFilterValue, errors.MsgBetweenValuesInvalid, filter.Field, len(values))
+		}
+	}
+	return nil
+}
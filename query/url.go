@@ -0,0 +1,413 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-17 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-17 16:00:00
+ * @FilePath: \go-sqlbuilder\query\url.go
+ * @Description: 查询字符串解析 - ?name__lk=foo&age__gte=18&sort=-created_at&page=2 => *Param
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/constant"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+)
+
+// 保留的查询参数名
+const (
+	URLParamSort     = "sort"      // 排序: sort=-created_at,name (- 为降序)
+	URLParamPage     = "page"      // 页码，从 1 开始
+	URLParamPageSize = "page_size" // 每页数量
+)
+
+// urlOperatorSeparator 字段名与操作符短码的分隔符: age__gte
+const urlOperatorSeparator = "__"
+
+// FieldType 查询参数值类型
+type FieldType int
+
+const (
+	FieldString FieldType = iota // 字符串
+	FieldInt                     // 整数 (int64)
+	FieldFloat                   // 浮点数 (float64)
+	FieldBool                    // 布尔值
+	FieldTime                    // 时间 (RFC3339 / 2006-01-02 15:04:05 / 2006-01-02)
+)
+
+// String 类型名称，用于错误信息
+func (t FieldType) String() string {
+	switch t {
+	case FieldInt:
+		return "integer"
+	case FieldFloat:
+		return "number"
+	case FieldBool:
+		return "boolean"
+	case FieldTime:
+		return "time"
+	default:
+		return "string"
+	}
+}
+
+// FieldSchema 可查询字段定义
+type FieldSchema struct {
+	Column    string    // 数据库列名，为空时与参数名相同
+	Type      FieldType // 值类型
+	Operators []string  // 允许的操作符短码 (QP_EQ / QP_LK ...)，为空时按类型取默认值
+	Sortable  bool      // 是否允许排序
+}
+
+// URLSchema 查询字符串模式: 仅 Fields 中的字段可过滤/排序
+type URLSchema struct {
+	Fields          map[string]FieldSchema // 参数名 -> 字段定义
+	DefaultPageSize int                    // 默认每页数量，为 0 时为 10
+	MaxPageSize     int                    // 每页数量上限，为 0 时不限制
+	DefaultSort     string                 // 未指定 sort 时的排序，格式同 sort 参数
+	IgnoreUnknown   bool                   // 忽略未定义的参数，默认返回错误
+}
+
+// FieldError 单个查询参数的错误
+type FieldError struct {
+	Param   string           // 查询参数名，如 age__gte
+	Value   string           // 原始值
+	Code    errors.ErrorCode // 错误码
+	Message string           // 错误描述
+}
+
+// Error 实现 error 接口
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// ParseError 查询字符串解析错误，包含全部出错的参数
+type ParseError struct {
+	Fields []FieldError
+}
+
+// Error 实现 error 接口
+func (e *ParseError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		parts[i] = field.Error()
+	}
+	return errors.MsgInvalidQueryParams + ": " + strings.Join(parts, "; ")
+}
+
+// Unwrap 展开为 *errors.AppError 列表，支持 errors.As
+func (e *ParseError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = errors.NewError(field.Code, field.Error())
+	}
+	return errs
+}
+
+// defaultURLOperators 各类型默认允许的操作符
+var defaultURLOperators = map[FieldType][]string{
+	FieldString: {QP_EQ, QP_NEQ, QP_LK, QP_ORLK, QP_IN, QP_NIN, QP_NULL},
+	FieldInt:    {QP_EQ, QP_NEQ, QP_GT, QP_GTE, QP_LT, QP_LTE, QP_ORGT, QP_ORLT, QP_IN, QP_NIN, QP_BTW, QP_NULL},
+	FieldFloat:  {QP_EQ, QP_NEQ, QP_GT, QP_GTE, QP_LT, QP_LTE, QP_ORGT, QP_ORLT, QP_BTW, QP_NULL},
+	FieldBool:   {QP_EQ, QP_NEQ, QP_NULL},
+	FieldTime:   {QP_EQ, QP_NEQ, QP_GT, QP_GTE, QP_LT, QP_LTE, QP_ORGT, QP_ORLT, QP_BTW, QP_NULL},
+}
+
+// urlTimeLayouts 时间值支持的格式
+var urlTimeLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// ParseURLValues 将查询字符串解析为 *Param
+//
+//	?name__lk=foo&age__gte=18&sort=-created_at&page=2
+//
+// 参数形如 field 或 field__op，op 为 operator.go 中的短码 (缺省为 eq)；eq 出现多个值时按 IN 处理 (无需允许 in)
+// orlk / orlt / orgt 条件合并为一个 OR 组，与其余条件以 AND 连接；pd / pa 等同于 sort 中的降序/升序
+// 全部参数解析完毕后统一返回 *ParseError，包含每个出错参数的错误码与描述
+func ParseURLValues(values url.Values, schema *URLSchema) (*Param, error) {
+	if schema == nil {
+		schema = &URLSchema{}
+	}
+
+	p := &urlParser{schema: schema, param: NewParam(), orGroup: NewFilterGroup(string(constant.LOGIC_OR))}
+	pageSize := schema.DefaultPageSize
+	if pageSize <= 0 {
+		pageSize = p.param.PageSize
+	}
+	page := 1
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sortValue := schema.DefaultSort
+	for _, key := range keys {
+		vals := values[key]
+		if len(vals) == 0 {
+			continue
+		}
+		switch key {
+		case URLParamSort:
+			sortValue = strings.Join(vals, ",")
+		case URLParamPage:
+			page = p.parsePositive(key, vals, errors.ErrorCodePageNumberInvalid, errors.MsgPageNumberInvalid, 0, page)
+		case URLParamPageSize:
+			pageSize = p.parsePositive(key, vals, errors.ErrorCodePageSizeInvalid,
+				fmt.Sprintf(errors.MsgPageSizeInvalid, schema.MaxPageSize), schema.MaxPageSize, pageSize)
+		default:
+			p.parseField(key, vals)
+		}
+	}
+	p.parseSort(sortValue)
+
+	if len(p.errs) > 0 {
+		return nil, &ParseError{Fields: p.errs}
+	}
+
+	if !p.orGroup.IsEmpty() {
+		p.param.AddFilterGroup(p.orGroup)
+	}
+	p.param.SetPage(page, pageSize)
+	return p.param, nil
+}
+
+// urlParser 单次解析的状态
+type urlParser struct {
+	schema  *URLSchema
+	param   *Param
+	orGroup *FilterGroup
+	errs    []FieldError
+}
+
+func (p *urlParser) fail(param, value string, code errors.ErrorCode, format string, args ...interface{}) {
+	p.errs = append(p.errs, FieldError{Param: param, Value: value, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// parseField 解析 field / field__op
+func (p *urlParser) parseField(key string, vals []string) {
+	name, op, hasOp := strings.Cut(key, urlOperatorSeparator)
+	op = strings.ToLower(op)
+	if !hasOp {
+		op = QP_EQ
+	}
+
+	field, ok := p.schema.Fields[name]
+	if !ok {
+		if !p.schema.IgnoreUnknown {
+			p.fail(key, strings.Join(vals, ","), errors.ErrorCodeInvalidFieldName, errors.MsgUnknownQueryParam, key)
+		}
+		return
+	}
+	column := field.Column
+	if column == "" {
+		column = name
+	}
+
+	// pd / pa: 排序短码
+	if op == QP_PD || op == QP_PA {
+		if !field.Sortable {
+			p.fail(key, "", errors.ErrorCodeInvalidFieldName, errors.MsgFieldNotSortable, name)
+			return
+		}
+		if op == QP_PD {
+			p.param.AddOrderDesc(column)
+		} else {
+			p.param.AddOrderAsc(column)
+		}
+		return
+	}
+
+	if !field.allows(op) {
+		p.fail(key, strings.Join(vals, ","), errors.ErrorCodeInvalidOperator, errors.MsgOperatorNotAllowed, op, name)
+		return
+	}
+
+	switch op {
+	case QP_IN:
+		p.addSet(key, column, OP_IN, vals, field.Type)
+	case QP_NIN:
+		p.addSet(key, column, Operator(constant.OP_NOT_IN), vals, field.Type)
+
+	case QP_BTW:
+		bounds := strings.Split(vals[len(vals)-1], ",")
+		if len(bounds) != 2 {
+			p.fail(key, vals[len(vals)-1], errors.ErrorCodeInvalidFilterValue, errors.MsgInvalidParamValue, vals[len(vals)-1], key, "min,max")
+			return
+		}
+		lower, okLower := p.convert(key, bounds[0], field.Type)
+		upper, okUpper := p.convert(key, bounds[1], field.Type)
+		if okLower && okUpper {
+			p.param.AddFilter(column, OP_BETWEEN, []interface{}{lower, upper})
+		}
+
+	case QP_NULL:
+		isNull, err := strconv.ParseBool(vals[len(vals)-1])
+		if err != nil {
+			p.fail(key, vals[len(vals)-1], errors.ErrorCodeInvalidFilterValue, errors.MsgInvalidParamValue, vals[len(vals)-1], key, FieldBool)
+			return
+		}
+		if isNull {
+			p.param.AddFilter(column, OP_IS_NULL, nil)
+		} else {
+			p.param.AddFilter(column, Operator(constant.OP_IS_NOT_NULL), nil)
+		}
+
+	case QP_EQ:
+		if len(vals) > 1 {
+			p.addSet(key, column, OP_IN, vals, field.Type)
+			return
+		}
+		p.addComparison(key, column, OP_EQ, vals, field.Type, false)
+	case QP_NEQ:
+		p.addComparison(key, column, OP_NEQ, vals, field.Type, false)
+	case QP_GT:
+		p.addComparison(key, column, OP_GT, vals, field.Type, false)
+	case QP_GTE:
+		p.addComparison(key, column, OP_GTE, vals, field.Type, false)
+	case QP_LT:
+		p.addComparison(key, column, OP_LT, vals, field.Type, false)
+	case QP_LTE:
+		p.addComparison(key, column, OP_LTE, vals, field.Type, false)
+	case QP_LK:
+		p.addComparison(key, column, OP_LIKE, vals, field.Type, false)
+	case QP_ORLK:
+		p.addComparison(key, column, OP_LIKE, vals, field.Type, true)
+	case QP_ORGT:
+		p.addComparison(key, column, OP_GT, vals, field.Type, true)
+	case QP_ORLT:
+		p.addComparison(key, column, OP_LT, vals, field.Type, true)
+
+	default:
+		p.fail(key, strings.Join(vals, ","), errors.ErrorCodeInvalidOperator, errors.MsgUnknownOperator, op)
+	}
+}
+
+// addComparison 单值比较，LIKE 两侧加 %，or 为 true 时加入 OR 组
+func (p *urlParser) addComparison(key, column string, operator Operator, vals []string, typ FieldType, or bool) {
+	for _, val := range vals {
+		var value interface{} = "%" + val + "%"
+		if operator != OP_LIKE {
+			converted, ok := p.convert(key, val, typ)
+			if !ok {
+				continue
+			}
+			value = converted
+		}
+		if or {
+			p.orGroup.AddFilter(column, operator, value)
+		} else {
+			p.param.AddFilter(column, operator, value)
+		}
+	}
+}
+
+// addSet IN / NOT IN，值可重复出现或以逗号分隔
+func (p *urlParser) addSet(key, column string, operator Operator, vals []string, typ FieldType) {
+	var items []interface{}
+	for _, val := range vals {
+		for _, item := range strings.Split(val, ",") {
+			if v, ok := p.convert(key, item, typ); ok {
+				items = append(items, v)
+			}
+		}
+	}
+	p.param.AddFilter(column, operator, items)
+}
+
+// parseSort 解析 -created_at,name
+func (p *urlParser) parseSort(value string) {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		desc := strings.HasPrefix(item, "-")
+		name := strings.TrimLeft(item, "+-")
+
+		field, ok := p.schema.Fields[name]
+		if !ok || !field.Sortable {
+			p.fail(URLParamSort, item, errors.ErrorCodeInvalidFieldName, errors.MsgFieldNotSortable, name)
+			continue
+		}
+		column := field.Column
+		if column == "" {
+			column = name
+		}
+		if desc {
+			p.param.AddOrderDesc(column)
+		} else {
+			p.param.AddOrderAsc(column)
+		}
+	}
+}
+
+// parsePositive 解析正整数参数，max 大于 0 时限制上限
+func (p *urlParser) parsePositive(key string, vals []string, code errors.ErrorCode, message string, max, fallback int) int {
+	raw := vals[len(vals)-1]
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || (max > 0 && n > max) {
+		p.fail(key, raw, code, "%s", message)
+		return fallback
+	}
+	return n
+}
+
+// convert 按字段类型转换值
+func (p *urlParser) convert(key, raw string, typ FieldType) (interface{}, bool) {
+	raw = strings.TrimSpace(raw)
+	var value interface{}
+	var err error
+	switch typ {
+	case FieldInt:
+		value, err = strconv.ParseInt(raw, 10, 64)
+	case FieldFloat:
+		value, err = strconv.ParseFloat(raw, 64)
+	case FieldBool:
+		value, err = strconv.ParseBool(raw)
+	case FieldTime:
+		value, err = parseURLTime(raw)
+	default:
+		value = raw
+	}
+	if err != nil {
+		p.fail(key, raw, errors.ErrorCodeInvalidFilterValue, errors.MsgInvalidParamValue, raw, key, typ)
+		return nil, false
+	}
+	return value, true
+}
+
+// allows 字段是否允许该操作符
+func (f FieldSchema) allows(op string) bool {
+	operators := f.Operators
+	if len(operators) == 0 {
+		operators = defaultURLOperators[f.Type]
+	}
+	for _, allowed := range operators {
+		if strings.EqualFold(allowed, op) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseURLTime(raw string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range urlTimeLayouts {
+		t, err := time.Parse(layout, raw)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
@@ -13,18 +13,26 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/kamalyes/go-sqlbuilder/column"
 	"github.com/kamalyes/go-sqlbuilder/constant"
 	"github.com/kamalyes/go-sqlbuilder/db"
 	"github.com/kamalyes/go-sqlbuilder/errors"
 	"github.com/kamalyes/go-sqlbuilder/meta"
+	"github.com/kamalyes/go-sqlbuilder/txctx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BaseRepository 基础仓储实现，包含通用的 CRUD 操作
 type BaseRepository[T any] struct {
 	db    db.Handler
 	table string
+
+	// 安全模式: 校验过滤/排序字段名与操作符，并由 GORM 按方言引用
+	safeMode  bool
+	validator *column.Validator
 }
 
 // NewBaseRepository 创建基础仓储
@@ -35,13 +43,28 @@ func NewBaseRepository[T any](dbHandler db.Handler, table string) *BaseRepositor
 	}
 }
 
+// WithSafeMode 开启安全模式，适用于过滤/排序字段来自外部输入的场景
+// 字段名须通过 validator 校验 (nil 时按 column.IdentifierPattern)，操作符须为已知操作符，
+// 排序方向须为 ASC/DESC，否则以 ErrorCodeInvalidFieldName / ErrorCodeInvalidOperator 拒绝
+func (r *BaseRepository[T]) WithSafeMode(validator *column.Validator) *BaseRepository[T] {
+	r.safeMode = true
+	r.validator = validator
+	return r
+}
+
+// session 返回绑定 ctx 的会话，ctx 中存在同一数据库的 txctx 事务时加入该事务
+// 事务属于其他数据库时会话携带错误，不会把写入落到其他库
+func (r *BaseRepository[T]) session(ctx context.Context) *gorm.DB {
+	return txctx.DB(ctx, r.db.DB())
+}
+
 // Create 创建单个记录
 func (r *BaseRepository[T]) Create(ctx context.Context, entity *T) (*T, error) {
 	if entity == nil {
 		return nil, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgEntityCannotBeNil)
 	}
 
-	result := r.db.DB().WithContext(ctx).Table(r.table).Create(entity)
+	result := r.session(ctx).Table(r.table).Create(entity)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -55,14 +78,14 @@ func (r *BaseRepository[T]) CreateBatch(ctx context.Context, entities ...*T) err
 		return nil
 	}
 
-	result := r.db.DB().WithContext(ctx).Table(r.table).CreateInBatches(entities, 100)
+	result := r.session(ctx).Table(r.table).CreateInBatches(entities, 100)
 	return result.Error
 }
 
 // Get 获取单个记录
 func (r *BaseRepository[T]) Get(ctx context.Context, id interface{}) (*T, error) {
 	var entity T
-	result := r.db.DB().WithContext(ctx).Table(r.table).Where("id = ?", id).First(&entity)
+	result := r.session(ctx).Table(r.table).Where("id = ?", id).First(&entity)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -77,8 +100,10 @@ func (r *BaseRepository[T]) GetByFilter(ctx context.Context, filter *Filter) (*T
 	}
 
 	var entity T
-	query := r.db.DB().WithContext(ctx).Table(r.table)
-	query = applyFilter(query, filter)
+	query, err := r.applyFilters(r.session(ctx).Table(r.table), filter)
+	if err != nil {
+		return nil, err
+	}
 
 	result := query.First(&entity)
 	if result.Error != nil {
@@ -95,9 +120,9 @@ func (r *BaseRepository[T]) GetByFilters(ctx context.Context, filters ...*Filter
 	}
 
 	var entity T
-	query := r.db.DB().WithContext(ctx).Table(r.table)
-	for _, filter := range filters {
-		query = applyFilter(query, filter)
+	query, err := r.applyFilters(r.session(ctx).Table(r.table), filters...)
+	if err != nil {
+		return nil, err
 	}
 
 	result := query.First(&entity)
@@ -115,16 +140,16 @@ func (r *BaseRepository[T]) List(ctx context.Context, query *Query) ([]*T, error
 	}
 
 	var entities []*T
-	db := r.db.DB().WithContext(ctx).Table(r.table)
 
 	// 应用过滤条件
-	for _, filter := range query.Filters {
-		db = applyFilter(db, filter)
+	db, err := r.applyFilters(r.session(ctx).Table(r.table), query.Filters...)
+	if err != nil {
+		return nil, err
 	}
 
 	// 应用排序
-	for _, order := range query.Orders {
-		db = db.Order(order.Field + " " + order.Direction)
+	if db, err = r.applyOrders(db, query.Orders); err != nil {
+		return nil, err
 	}
 
 	result := db.Find(&entities)
@@ -146,11 +171,11 @@ func (r *BaseRepository[T]) ListWithPagination(ctx context.Context, query *Query
 	}
 
 	var entities []*T
-	db := r.db.DB().WithContext(ctx).Table(r.table)
 
 	// 应用过滤条件
-	for _, filter := range query.Filters {
-		db = applyFilter(db, filter)
+	db, err := r.applyFilters(r.session(ctx).Table(r.table), query.Filters...)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// 计算总数
@@ -160,8 +185,8 @@ func (r *BaseRepository[T]) ListWithPagination(ctx context.Context, query *Query
 	page.Total = total
 
 	// 应用排序
-	for _, order := range query.Orders {
-		db = db.Order(order.Field + " " + order.Direction)
+	if db, err = r.applyOrders(db, query.Orders); err != nil {
+		return nil, nil, err
 	}
 
 	// 应用分页
@@ -180,7 +205,7 @@ func (r *BaseRepository[T]) Update(ctx context.Context, entity *T) (*T, error) {
 		return nil, errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgEntityCannotBeNil)
 	}
 
-	result := r.db.DB().WithContext(ctx).Table(r.table).Save(entity)
+	result := r.session(ctx).Table(r.table).Save(entity)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -195,7 +220,7 @@ func (r *BaseRepository[T]) UpdateBatch(ctx context.Context, entities ...*T) err
 	}
 
 	// 使用事务确保批量更新的一致性
-	return r.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.session(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, entity := range entities {
 			if err := tx.Table(r.table).Save(entity).Error; err != nil {
 				return err
@@ -215,9 +240,9 @@ func (r *BaseRepository[T]) UpdateByFilters(ctx context.Context, entity *T, filt
 		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgAtLeastOneFilterRequired)
 	}
 
-	db := r.db.DB().WithContext(ctx).Table(r.table)
-	for _, filter := range filters {
-		db = applyFilter(db, filter)
+	db, err := r.applyFilters(r.session(ctx).Table(r.table), filters...)
+	if err != nil {
+		return err
 	}
 
 	result := db.Updates(entity)
@@ -226,7 +251,7 @@ func (r *BaseRepository[T]) UpdateByFilters(ctx context.Context, entity *T, filt
 
 // Delete 删除单个记录
 func (r *BaseRepository[T]) Delete(ctx context.Context, id interface{}) error {
-	result := r.db.DB().WithContext(ctx).Table(r.table).Where("id = ?", id).Delete(new(T))
+	result := r.session(ctx).Table(r.table).Where("id = ?", id).Delete(new(T))
 	return result.Error
 }
 
@@ -236,7 +261,7 @@ func (r *BaseRepository[T]) DeleteBatch(ctx context.Context, ids ...interface{})
 		return nil
 	}
 
-	result := r.db.DB().WithContext(ctx).Table(r.table).Where("id IN ?", ids).Delete(new(T))
+	result := r.session(ctx).Table(r.table).Where("id IN ?", ids).Delete(new(T))
 	return result.Error
 }
 
@@ -246,9 +271,9 @@ func (r *BaseRepository[T]) DeleteByFilters(ctx context.Context, filters ...*Fil
 		return errors.NewError(errors.ErrorCodeInvalidInput, errors.MsgAtLeastOneFilterRequired)
 	}
 
-	db := r.db.DB().WithContext(ctx).Table(r.table)
-	for _, filter := range filters {
-		db = applyFilter(db, filter)
+	db, err := r.applyFilters(r.session(ctx).Table(r.table), filters...)
+	if err != nil {
+		return err
 	}
 
 	result := db.Delete(new(T))
@@ -257,7 +282,7 @@ func (r *BaseRepository[T]) DeleteByFilters(ctx context.Context, filters ...*Fil
 
 // Transaction 事务支持
 func (r *BaseRepository[T]) Transaction(ctx context.Context, fn func(tx Transaction) error) error {
-	return r.db.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.session(ctx).Transaction(func(tx *gorm.DB) error {
 		txWrapper := &transactionWrapper{db: db.NewGormHandler(tx)}
 		return fn(txWrapper)
 	})
@@ -266,10 +291,9 @@ func (r *BaseRepository[T]) Transaction(ctx context.Context, fn func(tx Transact
 // Count 计数
 func (r *BaseRepository[T]) Count(ctx context.Context, filters ...*Filter) (int64, error) {
 	var count int64
-	db := r.db.DB().WithContext(ctx).Table(r.table)
-
-	for _, filter := range filters {
-		db = applyFilter(db, filter)
+	db, err := r.applyFilters(r.session(ctx).Table(r.table), filters...)
+	if err != nil {
+		return 0, err
 	}
 
 	result := db.Count(&count)
@@ -286,6 +310,91 @@ func (r *BaseRepository[T]) Exists(ctx context.Context, filters ...*Filter) (boo
 	return count > 0, nil
 }
 
+// applyFilters 应用过滤条件，安全模式下校验字段名与操作符
+func (r *BaseRepository[T]) applyFilters(dbQuery *gorm.DB, filters ...*Filter) (*gorm.DB, error) {
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+		if !r.safeMode {
+			dbQuery = applyFilter(dbQuery, filter)
+			continue
+		}
+		expr, err := r.safeFilterExpr(filter)
+		if err != nil {
+			return nil, err
+		}
+		dbQuery = dbQuery.Where(expr)
+	}
+	return dbQuery, nil
+}
+
+// applyOrders 应用排序条件，安全模式下校验字段名与排序方向
+func (r *BaseRepository[T]) applyOrders(dbQuery *gorm.DB, orders []Order) (*gorm.DB, error) {
+	for _, order := range orders {
+		if !r.safeMode {
+			dbQuery = dbQuery.Order(order.Field + " " + order.Direction)
+			continue
+		}
+		if err := r.validator.ValidateName(order.Field); err != nil {
+			return nil, err
+		}
+		desc := strings.EqualFold(order.Direction, "DESC")
+		if !desc && order.Direction != "" && !strings.EqualFold(order.Direction, "ASC") {
+			return nil, errors.NewErrorf(errors.ErrorCodeInvalidInput, errors.MsgInvalidSortOrder, order.Direction)
+		}
+		dbQuery = dbQuery.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Field}, Desc: desc})
+	}
+	return dbQuery, nil
+}
+
+// safeFilterExpr 由过滤条件生成 GORM 表达式，列名经 clause.Column 按方言引用
+func (r *BaseRepository[T]) safeFilterExpr(filter *Filter) (clause.Expression, error) {
+	if err := r.validator.ValidateName(filter.Field); err != nil {
+		return nil, err
+	}
+	op, err := column.ParseOperator(filter.Operator)
+	if err != nil {
+		return nil, err
+	}
+
+	col := clause.Column{Name: filter.Field}
+	switch op {
+	case constant.OP_EQ:
+		return clause.Eq{Column: col, Value: filter.Value}, nil
+	case constant.OP_NEQ:
+		return clause.Neq{Column: col, Value: filter.Value}, nil
+	case constant.OP_GT:
+		return clause.Gt{Column: col, Value: filter.Value}, nil
+	case constant.OP_GTE:
+		return clause.Gte{Column: col, Value: filter.Value}, nil
+	case constant.OP_LT:
+		return clause.Lt{Column: col, Value: filter.Value}, nil
+	case constant.OP_LTE:
+		return clause.Lte{Column: col, Value: filter.Value}, nil
+	case constant.OP_LIKE:
+		return clause.Like{Column: col, Value: filter.Value}, nil
+	case constant.OP_NOT_LIKE:
+		return clause.Not(clause.Like{Column: col, Value: filter.Value}), nil
+	case constant.OP_IN:
+		return clause.IN{Column: col, Values: column.Values(filter.Value)}, nil
+	case constant.OP_NOT_IN:
+		return clause.Not(clause.IN{Column: col, Values: column.Values(filter.Value)}), nil
+	case constant.OP_BETWEEN:
+		values := column.Values(filter.Value)
+		if len(values) != 2 {
+			return nil, errors.NewError(errors.ErrorCodeInvalidFilterValue, errors.MsgInvalidWhereCondition)
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{col, values[0], values[1]}}, nil
+	case constant.OP_IS_NULL:
+		return clause.Eq{Column: col, Value: nil}, nil
+	case constant.OP_IS_NOT_NULL:
+		return clause.Neq{Column: col, Value: nil}, nil
+	default: // constant.OP_FIND_IN_SET
+		return clause.Expr{SQL: "FIND_IN_SET(?, ?) > 0", Vars: []interface{}{filter.Value, col}}, nil
+	}
+}
+
 // applyFilter 应用单个过滤条件到 GORM 查询
 func applyFilter(dbQuery *gorm.DB, filter *Filter) *gorm.DB {
 	if filter == nil {
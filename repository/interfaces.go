@@ -14,6 +14,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/kamalyes/go-sqlbuilder/column"
 	"github.com/kamalyes/go-sqlbuilder/constant"
 	"github.com/kamalyes/go-sqlbuilder/meta"
 )
@@ -70,6 +71,11 @@ func NewBetweenFilter(field string, min, max interface{}) *Filter {
 	return &Filter{Field: field, Operator: string(constant.OP_BETWEEN), Value: []interface{}{min, max}}
 }
 
+// NewConditionFilter 由类型安全的列条件创建过滤条件
+func NewConditionFilter(cond column.Condition) *Filter {
+	return &Filter{Field: cond.Field, Operator: string(cond.Operator), Value: cond.Value}
+}
+
 // Query 查询条件
 type Query struct {
 	Filters    []*Filter
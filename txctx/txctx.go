@@ -0,0 +1,124 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 16:00:00
+ * @FilePath: \go-sqlbuilder\txctx\txctx.go
+ * @Description: 通过 context 传播事务 - Builder / repository / persist 自动加入同一事务
+ *
+ * 用法:
+ *
+ *	manager := txctx.NewManager(gormDB)
+ *	err := manager.RunInTx(ctx, func(ctx context.Context) error {
+ *		if _, err := builder.WithContext(ctx).Table("orders").Insert(order).Exec(); err != nil {
+ *			return err
+ *		}
+ *		_, err := repo.Create(ctx, item)
+ *		return err
+ *	})
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package txctx
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"gorm.io/gorm"
+)
+
+// txKey context 中活动事务的键
+type txKey struct{}
+
+// With 返回携带事务的 context
+func With(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// From 取出 context 中的活动事务
+func From(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// InTx context 中是否存在活动事务
+func InTx(ctx context.Context) bool {
+	_, ok := From(ctx)
+	return ok
+}
+
+// Join 返回 db 可加入的 context 事务，ok 为 false 表示 context 中没有事务
+// 事务与 db 不属于同一个 *sql.DB 时返回错误，其他库的事务不能被加入
+func Join(ctx context.Context, db *gorm.DB) (tx *gorm.DB, ok bool, err error) {
+	tx, ok = From(ctx)
+	if !ok {
+		return nil, false, nil
+	}
+	if !SameDB(tx, db) {
+		return nil, false, errors.NewErrorf(errors.ErrorCodeAdapterNotSupported, errors.MsgTxContextMismatch, "transaction belongs to a different database")
+	}
+	return tx, true, nil
+}
+
+// SameDB a 与 b 是否使用同一个 *sql.DB (事务按其所属连接池比较)
+func SameDB(a, b *gorm.DB) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	poolA, errA := a.DB()
+	poolB, errB := b.DB()
+	return errA == nil && errB == nil && poolA == poolB
+}
+
+// DB 返回 context 中的活动事务，不存在时返回 db，均已绑定 ctx
+// 事务属于其他数据库时返回的会话携带错误，后续操作返回该错误
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	tx, ok, err := Join(ctx, db)
+	if err != nil {
+		session := db.WithContext(ctx)
+		_ = session.AddError(err)
+		return session
+	}
+	if ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// Manager 事务管理器
+type Manager struct {
+	db *gorm.DB
+}
+
+// NewManager 创建事务管理器
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// RunInTx 在事务中执行 fn，事务通过 fn 的 ctx 传播
+// fn 返回错误时回滚，panic 时回滚后继续抛出；ctx 中已有事务时以保存点嵌套执行
+func (m *Manager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.RunInTxWithOptions(ctx, nil, fn)
+}
+
+// RunInTxWithOptions 以指定的隔离级别 / 只读选项开启事务，嵌套调用时 opts 被忽略
+func (m *Manager) RunInTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if tx, ok := From(ctx); ok {
+		return tx.WithContext(ctx).Transaction(func(nested *gorm.DB) error {
+			return fn(With(ctx, nested))
+		})
+	}
+
+	var txOpts []*sql.TxOptions
+	if opts != nil {
+		txOpts = append(txOpts, opts)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(With(ctx, tx))
+	}, txOpts...)
+}
@@ -0,0 +1,189 @@
+/*
+ * @Author: kamalyes 501893067@qq.com
+ * @Date: 2025-11-18 16:00:00
+ * @LastEditors: kamalyes 501893067@qq.com
+ * @LastEditTime: 2025-11-18 16:00:00
+ * @FilePath: \go-sqlbuilder\txctx_test.go
+ * @Description: context 传播事务测试 - Builder / repository / persist 加入同一事务
+ *
+ * Copyright (c) 2025 by kamalyes, All Rights Reserved.
+ */
+package sqlbuilder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kamalyes/go-sqlbuilder/db"
+	"github.com/kamalyes/go-sqlbuilder/errors"
+	"github.com/kamalyes/go-sqlbuilder/persist"
+	"github.com/kamalyes/go-sqlbuilder/repository"
+	"github.com/kamalyes/go-sqlbuilder/txctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type txctxUser struct {
+	ID     int64
+	Name   string
+	Status string
+}
+
+func (txctxUser) TableName() string       { return "users" }
+func (u txctxUser) CacheKey() string      { return fmt.Sprintf("users:%d", u.ID) }
+func (txctxUser) CacheTTL() time.Duration { return time.Minute }
+
+// txctxHandler 仅提供 DB() 的 persist.DBHandler
+type txctxHandler struct {
+	persist.DBHandler
+	db *gorm.DB
+}
+
+func (h *txctxHandler) DB() *gorm.DB { return h.db }
+
+// Query 只查询 active 用户，模拟 handler 自带的作用域
+func (h *txctxHandler) Query(param *persist.QueryParam) *gorm.DB {
+	return param.Where(h.db.Where("status = ?", "active"))
+}
+
+// TestTxCtx_RunInTx 测试 Builder、仓储与 persist 通过 context 加入同一事务
+func TestTxCtx_RunInTx(t *testing.T) {
+	sqlDB := newSQLiteDB(t)
+	gormDB := newParamGormDB(t, sqlDB)
+	b, err := New(gormDB)
+	require.NoError(t, err)
+	repo := repository.NewBaseRepository[txctxUser](db.NewGormHandler(gormDB), "users")
+	handler := &txctxHandler{db: gormDB}
+	manager := txctx.NewManager(gormDB)
+
+	run := func(ctx context.Context, suffix string) {
+		assert.True(t, txctx.InTx(ctx))
+		txBuilder := b.WithContext(ctx)
+		assert.True(t, txBuilder.InTransaction())
+		insertTxUser(t, txBuilder, "Builder"+suffix)
+		_, err := repo.Create(ctx, &txctxUser{Name: "Repo" + suffix, Status: "active"})
+		require.NoError(t, err)
+		require.NoError(t, persist.Create(ctx, handler, &txctxUser{Name: "Persist" + suffix, Status: "active"}))
+
+		// 事务内可见未提交的写入
+		count, err := repo.Count(ctx, repository.NewInFilter("name", "Builder"+suffix, "Persist"+suffix))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		// persist 查询在事务内同样经由 handler 的 Query，作用域不丢失
+		hidden := txctxUser{Name: "Hidden" + suffix, Status: "inactive"}
+		require.NoError(t, persist.Create(ctx, handler, &hidden))
+		listed, err := persist.List[txctxUser](ctx, handler, persist.Filters{
+			persist.NewInFilter("name", []string{"Persist" + suffix, "Hidden" + suffix}),
+		}, nil)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "Persist"+suffix, listed[0].Name)
+	}
+
+	err = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+		run(ctx, "Committed")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, b.InTransaction())
+	assert.Equal(t, 3, countTxUsers(t, sqlDB, "BuilderCommitted", "RepoCommitted", "PersistCommitted"))
+
+	err = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+		run(ctx, "Aborted")
+		return errTxAbort
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	assert.Equal(t, 0, countTxUsers(t, sqlDB, "BuilderAborted", "RepoAborted", "PersistAborted"))
+
+	assert.Panics(t, func() {
+		_ = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+			run(ctx, "Panicked")
+			panic("boom")
+		})
+	})
+	assert.Equal(t, 0, countTxUsers(t, sqlDB, "BuilderPanicked", "RepoPanicked", "PersistPanicked"))
+	t.Logf("✓ context 事务提交与回滚测试通过")
+}
+
+// TestTxCtx_Nested 测试嵌套 RunInTx 与 Builder.Transaction 基于保存点执行
+func TestTxCtx_Nested(t *testing.T) {
+	sqlDB := newSQLiteDB(t)
+	gormDB := newParamGormDB(t, sqlDB)
+	b, err := New(gormDB)
+	require.NoError(t, err)
+	manager := txctx.NewManager(gormDB)
+
+	err = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+		insertTxUser(t, b.WithContext(ctx), "Outer")
+
+		err := manager.RunInTx(ctx, func(ctx context.Context) error {
+			insertTxUser(t, b.WithContext(ctx), "InnerFailed")
+			return errTxAbort
+		})
+		assert.ErrorIs(t, err, errTxAbort)
+
+		require.NoError(t, manager.RunInTx(ctx, func(ctx context.Context) error {
+			insertTxUser(t, b.WithContext(ctx), "Inner")
+			return nil
+		}))
+
+		return b.WithContext(ctx).Transaction(func(tx *Builder) error {
+			insertTxUser(t, tx, "BuilderSavepoint")
+			return errTxAbort
+		})
+	})
+	assert.ErrorIs(t, err, errTxAbort)
+	assert.Equal(t, 0, countTxUsers(t, sqlDB, "Outer", "Inner", "InnerFailed", "BuilderSavepoint"))
+
+	err = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+		insertTxUser(t, b.WithContext(ctx), "Outer")
+		_ = b.WithContext(ctx).Transaction(func(tx *Builder) error {
+			insertTxUser(t, tx, "BuilderSavepoint")
+			return errTxAbort
+		})
+		return manager.RunInTx(ctx, func(ctx context.Context) error {
+			insertTxUser(t, b.WithContext(ctx), "Inner")
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, countTxUsers(t, sqlDB, "Outer", "Inner"))
+	assert.Equal(t, 0, countTxUsers(t, sqlDB, "BuilderSavepoint"))
+	t.Logf("✓ context 嵌套事务测试通过")
+}
+
+// TestTxCtx_Mismatch 测试非 GORM 构建器或其他数据库不会加入 context 事务
+func TestTxCtx_Mismatch(t *testing.T) {
+	sqlDB := newSQLiteDB(t)
+	gormDB := newParamGormDB(t, sqlDB)
+	otherDB := newSQLiteDB(t)
+	otherGorm := newParamGormDB(t, otherDB)
+	otherBuilder, err := New(otherGorm)
+	require.NoError(t, err)
+	sqlxBuilder := newSQLiteBuilder(t, sqlDB)
+	otherRepo := repository.NewBaseRepository[txctxUser](db.NewGormHandler(otherGorm), "users")
+	otherHandler := &txctxHandler{db: otherGorm}
+
+	err = txctx.NewManager(gormDB).RunInTx(context.Background(), func(ctx context.Context) error {
+		for _, b := range []*Builder{sqlxBuilder, otherBuilder} {
+			txBuilder := b.WithContext(ctx)
+			assert.False(t, txBuilder.InTransaction())
+			assert.True(t, errors.IsErrorCode(txBuilder.Err(), errors.ErrorCodeAdapterNotSupported))
+			_, err := txBuilder.Table("users").Insert(map[string]interface{}{"name": "Leaked"}).Exec()
+			assert.Error(t, err)
+		}
+
+		_, err := otherRepo.Create(ctx, &txctxUser{Name: "Leaked", Status: "active"})
+		assert.True(t, errors.IsErrorCode(err, errors.ErrorCodeAdapterNotSupported))
+		assert.Error(t, persist.Create(ctx, otherHandler, &txctxUser{Name: "Leaked", Status: "active"}))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, countTxUsers(t, sqlDB, "Leaked"))
+	assert.Equal(t, 0, countTxUsers(t, otherDB, "Leaked"))
+	t.Logf("✓ context 事务数据库不匹配测试通过")
+}
@@ -59,6 +59,9 @@ type BusinessError = errors.AppError
 // Paging - 分页信息
 type Paging = meta.Paging
 
+// CursorPage - 游标分页信息
+type CursorPage = meta.CursorPage
+
 // ==================== Repository 工厂函数 ====================
 
 // NewBaseRepository - 创建基础仓储
@@ -189,6 +192,26 @@ func IsAlreadyExists(err error) bool {
 	return errors.IsAlreadyExists(err)
 }
 
+// ClassifyDBError - 将驱动错误映射为 BusinessError，原始错误可通过 Unwrap 取得
+func ClassifyDBError(err error) error {
+	return errors.ClassifyDBError(err)
+}
+
+// IsDuplicate - 检查是否是唯一约束冲突
+func IsDuplicate(err error) bool {
+	return errors.IsDuplicate(err)
+}
+
+// IsForeignKeyViolation - 检查是否是外键约束冲突
+func IsForeignKeyViolation(err error) bool {
+	return errors.IsForeignKeyViolation(err)
+}
+
+// IsRetryable - 检查是否是可重试的事务冲突 (死锁、序列化失败等)
+func IsRetryable(err error) bool {
+	return errors.IsRetryable(err)
+}
+
 // ==================== Paging 工厂函数 ====================
 
 // NewPaging - 创建分页信息